@@ -2,12 +2,14 @@ module gce
 
 go 1.23.5
 
-require github.com/stretchr/testify v1.10.0
+require (
+	github.com/charmbracelet/log v0.4.0
+	github.com/stretchr/testify v1.10.0
+)
 
 require (
 	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
 	github.com/charmbracelet/lipgloss v0.10.0 // indirect
-	github.com/charmbracelet/log v0.4.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/go-logfmt/logfmt v0.6.0 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
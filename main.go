@@ -2,21 +2,38 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"flag"
 	"fmt"
 	"gce/pkg/chess"
 	"gce/pkg/engine"
+	"gce/pkg/uci"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
 	"sort"
 	"strings"
+
+	"github.com/charmbracelet/log"
 )
 
 func main() {
+	useUci := flag.Bool("uci", false, "Communicates using the UCI protocol through stdin/stdout")
+	flag.Parse()
+
 	go func() {
 		http.ListenAndServe("localhost:6060", nil)
 	}()
 
+	if *useUci {
+		handler := uci.NewHandler(os.Stdout)
+		if err := handler.Run(os.Stdin); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	var depth uint
 	fmt.Print("Depth: ")
 	fmt.Scanln(&depth)
@@ -34,16 +51,21 @@ func main() {
 	}
 
 	b := chess.FenToBoard(fen)
+	config := engine.NewSearchConfig()
+	config.Progress = engine.ProgressFunc(func(stats engine.SearchStats) {
+		fmt.Printf("\rNodes per second: %10d", stats.NPS())
+	})
 	// fmt.Println(engine.Perft(b, depth))
 	for {
 		vb := b.VisualBoard()
 		fmt.Println(vb.String())
 
+		b.UpdateResult()
 		if b.IsMated() {
 			fmt.Println("CHECKMATE BABY!!!!")
 			break
-		} else if b.IsDraw() {
-			fmt.Println("DRAW")
+		} else if b.Ctx.Result == chess.Draw || b.ClaimDraw() {
+			fmt.Println("DRAW:", b.Ctx.Termination)
 			break
 		}
 
@@ -54,20 +76,26 @@ func main() {
 		if moveNotation == "q" {
 			break
 		} else if moveNotation == "eval" {
-			returnCh := make(chan engine.AnalysisReport)
-			nodesCountch := make(chan struct{})
+			// Ctrl+C stops the analysis, showing the best line found so far
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			analysisReport, err := engine.AnalysisWithContext(ctx, b, engine.SearchLimits{Depth: depth}, config, nil)
+			stop()
 
-			analysisReport := engine.AnalysisByDepth(b, depth, returnCh, nodesCountch)
-			bestBoard, evaluation := analysisReport.BestBoard, analysisReport.Evaluation
+			bestBoard, stats := analysisReport.BestBoard, analysisReport.Stats
+			fmt.Println()
+			if err != nil {
+				fmt.Println("Analysis stopped:", err)
+			}
 			fmt.Println("=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=")
-			fmt.Printf("Evaluation: %.2f\n", evaluation)
+			fmt.Printf("Depth: %d (selective %d)\n", analysisReport.Depth, stats.SelDepth)
+			fmt.Printf("Nodes: %d (quiescence %d), time: %s, NPS: %d\n", stats.Nodes, stats.QNodes, stats.Elapsed, stats.NPS())
+			fmt.Printf("TT hit rate: %.2f%%, cutoffs: %d, PVS re-searches: %d\n", stats.TTHitRate(), stats.Cutoffs, stats.Researches)
+			fmt.Printf("Aspiration fail highs: %d, fail lows: %d\n", stats.FailHighs, stats.FailLows)
+			fmt.Printf("Evaluation: %s\n", analysisReport.EvaluationString())
 			fmt.Println(analysisReport.GetEngineLine())
 			fmt.Println("Final position after engine line:")
 			fmt.Println(bestBoard.VisualBoard().String())
 			fmt.Println("=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=-=")
-
-			close(returnCh)
-			close(nodesCountch)
 			continue
 		} else if moveNotation == "list" {
 			allLegalMoves := engine.MoveSlice(b.AllLegalMoves())
@@ -91,6 +119,31 @@ func main() {
 			}
 			fmt.Println(nodes)
 			continue
+		} else if moveNotation == "perftdiff" {
+			depth := uint(0)
+			fmt.Print("Depth: ")
+			fmt.Scanln(&depth)
+			var path string
+			fmt.Print("Reference file (e.g. output of Stockfish's go perft): ")
+			fmt.Scanln(&path)
+			file, err := os.Open(path)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			reference, err := engine.ParsePerftDivide(file)
+			file.Close()
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+
+			diffs := engine.PerftDivideDiff(b, depth, reference)
+			for _, diff := range diffs {
+				fmt.Println(diff)
+			}
+			fmt.Println("Moves with different nodes:", len(diffs))
+			continue
 		}
 
 		move, err := b.ParseMove(moveNotation)
@@ -0,0 +1,205 @@
+package chess
+
+import (
+	"math/bits"
+
+	"github.com/charmbracelet/log"
+)
+
+// Precomputed attack tables, indexed by the square (index of the bit, 0 is H1).
+// See https://www.chessprogramming.org/Attack_and_Defend_Maps
+var (
+	knightAttacks [64]uint64
+	kingAttacks   [64]uint64
+	pawnAttacks   [2][64]uint64 // [color][square], white is 0
+	bishopMagics  [64]magic
+	rookMagics    [64]magic
+	// Squares between two squares on the same rank, file or diagonal, without them. 0 if they aren't aligned
+	between [64][64]uint64
+)
+
+// Directions as (rank, column) steps. Columns are counted from the H file, like the bits.
+var (
+	bishopSteps = [4][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+	rookSteps   = [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	knightSteps = [8][2]int{{2, 1}, {2, -1}, {-2, 1}, {-2, -1}, {1, 2}, {1, -2}, {-1, 2}, {-1, -2}}
+	kingSteps   = [8][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+)
+
+// magic finds the attacks of a slider in a table, by multiplying the pieces that can block it by
+// a magic number that maps every combination of blockers to a different index (or one with the same attacks).
+// See https://www.chessprogramming.org/Magic_Bitboards
+type magic struct {
+	mask    uint64 // Squares where a piece can block the slider, the last square of each ray doesn't matter
+	number  uint64
+	shift   uint
+	attacks []uint64
+}
+
+func (m *magic) index(occupied uint64) uint64 {
+	return (occupied & m.mask) * m.number >> m.shift
+}
+
+func init() {
+	for square := range 64 {
+		knightAttacks[square] = leaperAttacks(square, knightSteps[:])
+		kingAttacks[square] = leaperAttacks(square, kingSteps[:])
+		pawnAttacks[0][square] = leaperAttacks(square, [][2]int{{1, 1}, {1, -1}})
+		pawnAttacks[1][square] = leaperAttacks(square, [][2]int{{-1, 1}, {-1, -1}})
+		bishopMagics[square] = newMagic(square, bishopSteps[:], bishopMagicNumbers[square])
+		rookMagics[square] = newMagic(square, rookSteps[:], rookMagicNumbers[square])
+	}
+
+	for a := range 64 {
+		for b := range 64 {
+			if RookAttacks(a, 0)&(1<<b) != 0 {
+				between[a][b] = RookAttacks(a, 1<<b) & RookAttacks(b, 1<<a)
+			} else if BishopAttacks(a, 0)&(1<<b) != 0 {
+				between[a][b] = BishopAttacks(a, 1<<b) & BishopAttacks(b, 1<<a)
+			}
+		}
+	}
+}
+
+func isOnBoard(rank, col int) bool {
+	return rank >= 0 && rank < 8 && col >= 0 && col < 8
+}
+
+func leaperAttacks(square int, steps [][2]int) uint64 {
+	attacks := uint64(0)
+	rank, col := square/8, square%8
+	for _, step := range steps {
+		if r, c := rank+step[0], col+step[1]; isOnBoard(r, c) {
+			attacks |= 1 << (r*8 + c)
+		}
+	}
+	return attacks
+}
+
+// slidingAttacks returns the squares attacked by a slider walking each ray until it finds a piece.
+// It's slow, it's only used to fill the magic tables.
+func slidingAttacks(square int, occupied uint64, steps [][2]int) uint64 {
+	attacks := uint64(0)
+	for _, step := range steps {
+		for r, c := square/8+step[0], square%8+step[1]; isOnBoard(r, c); r, c = r+step[0], c+step[1] {
+			pos := uint64(1) << (r*8 + c)
+			attacks |= pos
+			if occupied&pos != 0 {
+				break
+			}
+		}
+	}
+	return attacks
+}
+
+// relevantOccupancy returns the squares of the rays without the last one, since a piece there
+// blocks nothing.
+func relevantOccupancy(square int, steps [][2]int) uint64 {
+	mask := uint64(0)
+	for _, step := range steps {
+		for r, c := square/8+step[0], square%8+step[1]; isOnBoard(r+step[0], c+step[1]); r, c = r+step[0], c+step[1] {
+			mask |= 1 << (r*8 + c)
+		}
+	}
+	return mask
+}
+
+// newMagic fills the attacks table of a slider on the square.
+func newMagic(square int, steps [][2]int, number uint64) magic {
+	m := magic{mask: relevantOccupancy(square, steps), number: number}
+	bitCount := bits.OnesCount64(m.mask)
+	m.shift = uint(64 - bitCount)
+	m.attacks = make([]uint64, 1<<bitCount)
+	filled := make([]bool, len(m.attacks))
+
+	// All the subsets of the mask, see https://www.chessprogramming.org/Traversing_Subsets_of_a_Set
+	for subset := uint64(0); ; {
+		attacks := slidingAttacks(square, subset, steps)
+		index := m.index(subset)
+		if filled[index] && m.attacks[index] != attacks {
+			log.Fatalf("Invalid magic number for square %d: %#x", square, number)
+		}
+		m.attacks[index], filled[index] = attacks, true
+
+		subset = (subset - m.mask) & m.mask
+		if subset == 0 {
+			break
+		}
+	}
+	return m
+}
+
+// Magic numbers that don't have collisions. They were found by trying random numbers with few bits set,
+// which is too slow to do every time the program starts.
+var bishopMagicNumbers = [64]uint64{
+	0x1002200101020088, 0x0104010202060200, 0x0810242044400580, 0x0084042080210000,
+	0x000A121018050860, 0x0116300420002900, 0x1018840188C00000, 0x4002420050080442,
+	0x006A108408408405, 0x8608600504250054, 0x0000100108410804, 0x0001880845100700,
+	0x2946141044200000, 0x0016008210404000, 0x0200004128284010, 0x0010021080841010,
+	0x0021804004210209, 0x401003080200A420, 0x0010004A02720020, 0x400802040420080C,
+	0x0104008611204007, 0x4621102200500404, 0x0009010600822104, 0x0200212202020250,
+	0x21024200D0100202, 0x0008208004010200, 0x0104100001004080, 0x4824080044021002,
+	0x9041001001004000, 0x0001004002005008, 0x0082021044108220, 0x0004008481461088,
+	0x0010880404081000, 0x0511010840E00840, 0x0004010400208420, 0x0980400808128200,
+	0x02201204000080E8, 0x4128100020190080, 0x0181021420A08400, 0x01A0850544010420,
+	0x144C10021000C882, 0x0502008220020806, 0x00A0082808040400, 0x082C004202208800,
+	0x2400202009015084, 0x0002600049014080, 0x040401022C142600, 0x00040C4400400024,
+	0x0000440404401000, 0x4085004202A00360, 0x0800002084500802, 0x2024000084041010,
+	0x0001A04003820610, 0x0901A0C401021001, 0x0004E00812108002, 0x1808080840802020,
+	0x0005008801011006, 0x0001002401041140, 0x0010801108611001, 0x200080114C840400,
+	0x29080000C2082200, 0x5814404AA2082202, 0x00000620282A0080, 0x5002080808004844,
+}
+
+var rookMagicNumbers = [64]uint64{
+	0x0980008011400020, 0x8340004410002000, 0x0880200090008268, 0x0080080080100004,
+	0x8100110004020800, 0x0300010004000822, 0x08801A0029000080, 0x8100050001204882,
+	0x0844800081400320, 0x0804402010004000, 0x0108802003100480, 0x0004808008001000,
+	0x0003001801001014, 0x0002000200041008, 0x0004008108042210, 0x0105000100009042,
+	0x0400808000400021, 0xC100404010002000, 0x8000818020011001, 0x0400808008001000,
+	0x4440808008000400, 0x1002008004000280, 0x40024400300D1248, 0x0010020000408104,
+	0x0101008200204200, 0x8020002040005000, 0x4100100080802000, 0x4008006A80100280,
+	0x0021008500100800, 0x5000040080800200, 0x0010040101000200, 0x6140004200008104,
+	0x4000400020800090, 0x2020002080804000, 0x0000408202002010, 0x0080100501000820,
+	0x0000800400800800, 0x000A200408014010, 0x0100800200800100, 0xA00800570200008C,
+	0x008000406000C010, 0x1040100028002000, 0x0048200100110040, 0x0190010080080800,
+	0x0404080004008080, 0x0082008004008002, 0x1002000801020004, 0x0010848505620004,
+	0x0000801840002080, 0x2002010C80403200, 0x9000114220010300, 0x0001000820100100,
+	0x000A800401080080, 0xC001400410200801, 0x4800480102300400, 0x1000010040840200,
+	0x0002800442162101, 0x4000810010204202, 0x0400201200084082, 0x8200210004081001,
+	0x1002001004200802, 0x0005000208040001, 0x0002002701AC0822, 0x000010250184004A,
+}
+
+// BishopAttacks returns the squares attacked by a bishop on the square, with the occupied squares blocking it.
+func BishopAttacks(square int, occupied uint64) uint64 {
+	m := &bishopMagics[square]
+	return m.attacks[m.index(occupied)]
+}
+
+// RookAttacks returns the squares attacked by a rook on the square, with the occupied squares blocking it.
+func RookAttacks(square int, occupied uint64) uint64 {
+	m := &rookMagics[square]
+	return m.attacks[m.index(occupied)]
+}
+
+// QueenAttacks returns the squares attacked by a queen on the square, with the occupied squares blocking it.
+func QueenAttacks(square int, occupied uint64) uint64 {
+	return BishopAttacks(square, occupied) | RookAttacks(square, occupied)
+}
+
+// KnightAttacks returns the squares attacked by a knight on the square.
+func KnightAttacks(square int) uint64 {
+	return knightAttacks[square]
+}
+
+// KingAttacks returns the squares attacked by a king on the square.
+func KingAttacks(square int) uint64 {
+	return kingAttacks[square]
+}
+
+// PawnAttacks returns the squares attacked by a pawn of the color on the square.
+func PawnAttacks(square int, isWhite bool) uint64 {
+	if isWhite {
+		return pawnAttacks[0][square]
+	}
+	return pawnAttacks[1][square]
+}
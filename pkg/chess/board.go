@@ -1,8 +1,6 @@
 package chess
 
-import (
-	"gce/pkg/utils"
-)
+import "math/bits"
 
 // Board represents a full board with pieces of both colors on it.
 type Board struct {
@@ -27,6 +25,23 @@ func NewBoard() *Board {
 	}
 }
 
+// Copy returns a deep copy of the board, so moves can be made and undone in the copy
+// without affecting the original board.
+func (b Board) Copy() *Board {
+	b.MovesDone = append(make([]Move, 0, cap(b.MovesDone)), b.MovesDone...)
+	b.PreviousCtx = append(make([]Context, 0, cap(b.PreviousCtx)), b.PreviousCtx...)
+	return &b
+}
+
+// startBoard returns a copy of the board before any move was made.
+func (b Board) startBoard() *Board {
+	start := b.Copy()
+	for len(start.MovesDone) > 0 {
+		start.UndoMove()
+	}
+	return start
+}
+
 func (b Board) IsValidPosition() bool {
 	// Check if there are no pieces in the same position
 	whitePieces := b.White.AllBoardMask()
@@ -44,56 +59,25 @@ func (b Board) IsValidPosition() bool {
 	return true
 }
 
+// AllLegalMoves returns the legal moves of the side to move, see GenerateLegalMoves.
 func (b Board) AllLegalMoves() []Move {
-	// if b.HashWithContext == 0 {
-	// 	b.HashWithContext = b.HashBoardWithContext()
-	// }
-
-	// if moves, ok := allLegalMovesHashTable[b.HashWithContext]; ok {
-	// 	return moves
-	// }
-
-	moves := b.GenerateAllMoves()
-	moves = utils.Filter(moves, func(m Move) bool {
-		b.MakePseudoLegalMove(m)
-		isValid := b.IsValidPosition()
-		b.UndoMove()
-
-		if isValid && m.IsCastling {
-			// Check if the mid square is attacked
-			midPoint := m.NewPiecePos
-			if m.NewPiecePos < m.OldPiecePos { // King side
-				midPoint = moveRight(m.OldPiecePos, 1)
-			} else { // Queen side
-				midPoint = moveLeft(m.OldPiecePos, 1)
-			}
-			move := Move{OldPiecePos: m.OldPiecePos, NewPiecePos: midPoint, PieceType: KingType}
-			b.MakePseudoLegalMove(move)
-			isValid = isValid && b.IsValidPosition()
-			b.UndoMove()
-		}
-
-		return isValid
-	})
-	utils.ForEach(moves, func(m *Move) {
-		m.isLegal = true
-	})
-	// allLegalMovesHashTable[b.HashWithContext] = moves
+	var list MoveList
+	b.GenerateLegalMoves(&list)
+	moves := make([]Move, list.Len())
+	for i, packed := range list.Moves() {
+		moves[i] = b.UnpackLegalMove(packed)
+	}
 	return moves
 }
 
+// GenerateAllMoves returns the pseudo legal moves of the side to move, see GeneratePseudoLegalMoves.
 func (b Board) GenerateAllMoves() []Move {
-	var pb *PartialBoard
-	if b.Ctx.WhiteTurn {
-		pb = &b.White
-	} else {
-		pb = &b.Black
+	var list MoveList
+	b.GeneratePseudoLegalMoves(&list)
+	moves := make([]Move, list.Len())
+	for i, packed := range list.Moves() {
+		moves[i] = b.UnpackMove(packed)
 	}
-
-	// "Normal" moves (including En passant and promotion)
-	moves := pb.AllPossibleMoves(b)
-	moves = append(moves, pb.AllCastlingMoves(b)...)
-
 	return moves
 }
 
@@ -113,125 +97,162 @@ func (b *Board) IsMated() bool {
 
 	possibleDefensiveMoves := b.AllLegalMoves()
 	b.Ctx.IsMatedCache = len(possibleDefensiveMoves) == 0
-	if b.Ctx.IsMatedCache {
-		if b.Ctx.WhiteTurn {
-			b.Ctx.Result = BlackWin
-		} else {
-			b.Ctx.Result = WhiteWin
-		}
-	}
 	return b.Ctx.IsMatedCache
 }
 
-// IsKingInCheck returns true if the king is in check.
-// It generates moves for the king as if it was every piece on the board.
-// Diagonal, horizontal, vertical, and knight moves are checked.
+// IsKingInCheck returns true if the king of the side to move is in check.
 func (b *Board) IsKingInCheck() bool {
-	var kingPos uint64
-	var ourPb, enemyPb PartialBoard
-	var dirFnToCheckPawnAttack func(uint64, int) uint64
-	if b.Ctx.WhiteTurn {
-		kingPos = b.White.King.Board
-		ourPb = b.White
-		enemyPb = b.Black
-		dirFnToCheckPawnAttack = GetDirectionFunc(directionUp)
-	} else {
-		kingPos = b.Black.King.Board
-		ourPb = b.Black
-		enemyPb = b.White
-		dirFnToCheckPawnAttack = GetDirectionFunc(directionDown)
-	}
-
-	ourPbMask := ourPb.AllBoardMask()
-	enemyPbMask := enemyPb.AllBoardMask()
-	enemySlidersAndKingMask := enemyPb.Bishops.Board | enemyPb.Rooks.Board | enemyPb.Queens.Board | enemyPb.King.Board
-	directions := []int{directionUp, directionDown, directionLeft, directionRight, directionUpLeft, directionUpRight, directionDownLeft, directionDownRight}
-	for _, direction := range directions {
-		dirFn := GetDirectionFunc(direction)
-		for i := 1; i < 8; i++ {
-			newPos := dirFn(kingPos, i)
-			if newPos == 0 || newPos&ourPbMask != 0 {
-				break
-			}
-
-			if newPos&enemyPbMask != 0 {
-				if newPos&enemySlidersAndKingMask == 0 { // if its a pawn or knight should break
-					break
-				}
-
-				kingCollision := newPos&enemyPb.King.Board != 0
-				// Conditional rule of propositional logic (If A then B)
-				// If the piece is a king, it can only attack the king if it's one rank ahead.
-				// If King then i == 1 to the statement to be true.
-				// See https://plato.stanford.edu/entries/logic-conditionals/
-				// A -> B is equivalent to !A || B
-				if kingCollision && i == 1 {
-					return true
-				}
-
-				if newPos&enemyPb.Bishops.Board != 0 {
-					if direction == directionUpLeft || direction == directionUpRight || direction == directionDownLeft || direction == directionDownRight {
-						return true
-					}
-				}
-				if newPos&enemyPb.Rooks.Board != 0 {
-					if direction == directionUp || direction == directionDown || direction == directionLeft || direction == directionRight {
-						return true
-					}
-				}
-
-				if newPos&enemyPb.Queens.Board != 0 {
-					return true
-				}
-			}
-		}
+	king := b.side(b.Ctx.WhiteTurn).King.Board
+	if king == 0 {
+		return false
 	}
-	// check for pawn attack
-	pawn1 := dirFnToCheckPawnAttack(moveLeft(kingPos, 1), 1)
-	pawn2 := dirFnToCheckPawnAttack(moveRight(kingPos, 1), 1)
-	if pawn1 != 0 && pawn1&enemyPb.Pawns.Board != 0 {
-		return true
+	return b.IsSquareAttacked(bits.TrailingZeros64(king), !b.Ctx.WhiteTurn)
+}
+
+// IsDraw returns true if the game is drawn or if the player to move can claim a draw.
+// Like the other queries, it doesn't change the result, see UpdateResult and ClaimDraw.
+func (b *Board) IsDraw() bool {
+	return b.IsAutomaticDraw() || b.CanClaimDraw()
+}
+
+// IsAutomaticDraw returns true if the game is drawn without any player claiming it:
+// stalemate, insufficient material, fivefold repetition or the 75 moves rule.
+func (b *Board) IsAutomaticDraw() bool {
+	_, ok := b.automaticDraw()
+	return ok
+}
+
+func (b *Board) automaticDraw() (Termination, bool) {
+	if b.HasInsufficientMaterial() {
+		return InsufficientMaterial, true
 	}
-	if pawn2 != 0 && pawn2&enemyPb.Pawns.Board != 0 {
-		return true
+
+	if b.RepetitionCount() >= 5 {
+		return FivefoldRepetition, true
 	}
 
-	knightMoves := []func(uint64) uint64{
-		moveL1, moveL2, moveL3, moveL4, moveL5, moveL6, moveL7, moveL8,
+	// 75 moves rule, unless the last move was a checkmate
+	if b.Ctx.HalfMoves >= 150 && !b.IsMated() {
+		return SeventyFiveMoves, true
 	}
-	for _, knightMoveFn := range knightMoves {
-		newPos := knightMoveFn(kingPos)
-		if newPos == 0 {
-			continue
-		}
 
-		if newPos&enemyPb.Knights.Board != 0 {
-			return true
+	// Stalemate
+	if !b.IsKingInCheck() {
+		allLegalMoves := b.AllLegalMoves()
+		if len(allLegalMoves) == 0 {
+			return Stalemate, true
 		}
 	}
 
-	return false
+	return NoTermination, false
 }
 
-func (b *Board) IsDraw() bool {
-	// Threesold repetition
+// CanClaimDraw returns true if the player to move can claim a draw:
+// threefold repetition or the fifty moves rule. It doesn't change the result, the game goes on
+// until the draw is claimed with ClaimDraw.
+func (b *Board) CanClaimDraw() bool {
+	_, ok := b.claimableDraw()
+	return ok
+}
+
+// ClaimDraw ends the game as a draw if the player to move can claim it, otherwise it returns false.
+func (b *Board) ClaimDraw() bool {
+	termination, ok := b.claimableDraw()
+	if ok {
+		b.setDraw(termination)
+	}
+	return ok
+}
+
+func (b *Board) claimableDraw() (Termination, bool) {
+	if b.RepetitionCount() >= 3 {
+		return ThreefoldRepetition, true
+	}
 
 	// Fifty moves rule
-	if b.Ctx.HalfMoves >= 100 {
-		b.Ctx.Result = Draw
-		return true
+	if b.Ctx.HalfMoves >= 100 && !b.IsMated() {
+		return FiftyMoves, true
 	}
 
-	// Stalemate
-	if !b.IsKingInCheck() {
-		allLegalMoves := b.AllLegalMoves()
-		if len(allLegalMoves) == 0 {
-			b.Ctx.Result = Draw
-			return true
+	return NoTermination, false
+}
+
+func (b *Board) setDraw(termination Termination) {
+	b.Ctx.Result = Draw
+	b.Ctx.Termination = termination
+}
+
+// lossFor returns the result of a game lost by the color.
+func lossFor(isWhite bool) Result {
+	if isWhite {
+		return BlackWin
+	}
+	return WhiteWin
+}
+
+// UpdateResult sets the result if the game ended without any player claiming it: by checkmate or by
+// an automatic draw. It returns the result, NoResult if the game goes on.
+func (b *Board) UpdateResult() Result {
+	b.Ctx.Result, b.Ctx.Termination = b.currentResult()
+	return b.Ctx.Result
+}
+
+// currentResult returns the result already set (e.g. a resignation or a claimed draw) or,
+// if there isn't one, the result of a checkmate or an automatic draw, without setting it.
+func (b *Board) currentResult() (Result, Termination) {
+	if b.Ctx.Result != NoResult {
+		return b.Ctx.Result, b.Ctx.Termination
+	}
+	if b.IsMated() {
+		return lossFor(b.Ctx.WhiteTurn), Checkmate
+	}
+	if termination, ok := b.automaticDraw(); ok {
+		return Draw, termination
+	}
+	return NoResult, NoTermination
+}
+
+// Resign ends the game as a loss for the color, which doesn't need to be the side to move.
+func (b *Board) Resign(isWhite bool) {
+	b.Ctx.Result = lossFor(isWhite)
+	b.Ctx.Termination = Resignation
+}
+
+// RepetitionCount returns how many times the current position occurred in the game, including now.
+// Positions are compared by their hash, so side to move, castling rights and en passant are considered.
+func (b Board) RepetitionCount() int {
+	count := 1
+	// Positions before the last capture or pawn move can't be repeated.
+	// Only positions with the same side to move are checked (every 2 plies)
+	oldest := max(len(b.PreviousCtx)-int(b.Ctx.HalfMoves), 0)
+	for i := len(b.PreviousCtx) - 2; i >= oldest; i -= 2 {
+		if b.PreviousCtx[i].hash == b.Ctx.hash {
+			count++
 		}
 	}
+	return count
+}
+
+// HasInsufficientMaterial returns true if neither player can checkmate:
+// K vs K, K+N vs K, K+B vs K, or only bishops on squares of the same color.
+func (b Board) HasInsufficientMaterial() bool {
+	heavyPieces := b.White.Pawns.Board | b.White.Rooks.Board | b.White.Queens.Board |
+		b.Black.Pawns.Board | b.Black.Rooks.Board | b.Black.Queens.Board
+	if heavyPieces != 0 {
+		return false
+	}
 
-	return false
+	knights := b.White.Knights.Board | b.Black.Knights.Board
+	bishops := b.White.Bishops.Board | b.Black.Bishops.Board
+	minorPiecesCount := bits.OnesCount64(knights | bishops)
+	if minorPiecesCount <= 1 {
+		return true
+	}
+	// More than one minor piece, only a draw if all of them are bishops on the same color
+	if knights != 0 {
+		return false
+	}
+	return bishops&lightSquares == 0 || bishops&^lightSquares == 0
 }
 
 func (b Board) MaterialValueBalance() int64 {
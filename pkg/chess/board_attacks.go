@@ -0,0 +1,72 @@
+package chess
+
+import "math/bits"
+
+// side returns the pieces of a color.
+func (b *Board) side(isWhite bool) *PartialBoard {
+	if isWhite {
+		return &b.White
+	}
+	return &b.Black
+}
+
+// AttackersTo returns the pieces of the color that attack the square (index of its bit, 0 is H1).
+// Pieces of any color block the sliders.
+func (b *Board) AttackersTo(square int, byWhite bool) uint64 {
+	return b.side(byWhite).AttackersTo(square, b.occupied(), byWhite)
+}
+
+// IsSquareAttacked returns true if any piece of the color attacks the square.
+// It's faster than AttackersTo, since it stops at the first attacker found.
+func (b *Board) IsSquareAttacked(square int, byWhite bool) bool {
+	return b.side(byWhite).attacksSquare(square, b.occupied(), byWhite)
+}
+
+// AttackMap returns the squares attacked by the pieces of the color, including squares with pieces
+// of the same color (defended pieces).
+func (b *Board) AttackMap(byWhite bool) uint64 {
+	return b.side(byWhite).Attacks(b.occupied(), byWhite)
+}
+
+// AttackersTo returns the pieces that attack the square, with the occupied squares blocking the sliders.
+// isWhite is the color of the pieces, needed for the direction of the pawns.
+// Removing pieces from occupied reveals the sliders behind them (x-rays).
+func (pb *PartialBoard) AttackersTo(square int, occupied uint64, isWhite bool) uint64 {
+	// A pawn attacks the square if a pawn of the other color on the square would attack it
+	return PawnAttacks(square, !isWhite)&pb.Pawns.Board |
+		KnightAttacks(square)&pb.Knights.Board |
+		KingAttacks(square)&pb.King.Board |
+		BishopAttacks(square, occupied)&(pb.Bishops.Board|pb.Queens.Board) |
+		RookAttacks(square, occupied)&(pb.Rooks.Board|pb.Queens.Board)
+}
+
+// attacksSquare is like AttackersTo != 0, but it checks the pieces one type at a time.
+func (pb *PartialBoard) attacksSquare(square int, occupied uint64, isWhite bool) bool {
+	return PawnAttacks(square, !isWhite)&pb.Pawns.Board != 0 ||
+		KnightAttacks(square)&pb.Knights.Board != 0 ||
+		KingAttacks(square)&pb.King.Board != 0 ||
+		BishopAttacks(square, occupied)&(pb.Bishops.Board|pb.Queens.Board) != 0 ||
+		RookAttacks(square, occupied)&(pb.Rooks.Board|pb.Queens.Board) != 0
+}
+
+// Attacks returns the squares attacked by the pieces, with the occupied squares blocking the sliders.
+// isWhite is the color of the pieces, needed for the direction of the pawns.
+func (pb *PartialBoard) Attacks(occupied uint64, isWhite bool) uint64 {
+	attacks := uint64(0)
+	for pieces := pb.Pawns.Board; pieces != 0; pieces &= pieces - 1 {
+		attacks |= PawnAttacks(bits.TrailingZeros64(pieces), isWhite)
+	}
+	for pieces := pb.Knights.Board; pieces != 0; pieces &= pieces - 1 {
+		attacks |= KnightAttacks(bits.TrailingZeros64(pieces))
+	}
+	for pieces := pb.Bishops.Board | pb.Queens.Board; pieces != 0; pieces &= pieces - 1 {
+		attacks |= BishopAttacks(bits.TrailingZeros64(pieces), occupied)
+	}
+	for pieces := pb.Rooks.Board | pb.Queens.Board; pieces != 0; pieces &= pieces - 1 {
+		attacks |= RookAttacks(bits.TrailingZeros64(pieces), occupied)
+	}
+	if pb.King.Board != 0 {
+		attacks |= KingAttacks(bits.TrailingZeros64(pb.King.Board))
+	}
+	return attacks
+}
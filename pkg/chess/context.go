@@ -1,12 +1,67 @@
 package chess
 
+// Result is the result of the game.
+type Result uint
+
 const (
-	NoResult uint = iota
+	NoResult Result = iota
 	WhiteWin
 	BlackWin
 	Draw
 )
 
+// String returns the result as written in PGN.
+func (r Result) String() string {
+	switch r {
+	case WhiteWin:
+		return "1-0"
+	case BlackWin:
+		return "0-1"
+	case Draw:
+		return "1/2-1/2"
+	default:
+		return "*"
+	}
+}
+
+// Termination is the reason why the game ended.
+type Termination uint
+
+const (
+	NoTermination Termination = iota
+	Checkmate
+	Resignation
+	Stalemate
+	FiftyMoves
+	ThreefoldRepetition
+	FivefoldRepetition
+	InsufficientMaterial
+	SeventyFiveMoves
+)
+
+func (t Termination) String() string {
+	switch t {
+	case Checkmate:
+		return "checkmate"
+	case Resignation:
+		return "resignation"
+	case Stalemate:
+		return "stalemate"
+	case FiftyMoves:
+		return "fifty moves rule"
+	case ThreefoldRepetition:
+		return "threefold repetition"
+	case FivefoldRepetition:
+		return "fivefold repetition"
+	case InsufficientMaterial:
+		return "insufficient material"
+	case SeventyFiveMoves:
+		return "75 moves rule"
+	default:
+		return ""
+	}
+}
+
 type ContextCache struct {
 	IsDrawCache           bool
 	IsDrawCacheSet        bool
@@ -26,5 +81,45 @@ type Context struct {
 	EnPassant              uint64
 	HalfMoves              uint
 	MoveNumber             uint
-	Result                 uint
+	Result                 Result
+	Termination            Termination
+
+	hash uint64 // Zobrist hash of the position, see Board.Hash
+}
+
+// ResultDescription returns a human readable description of the result, e.g. "White wins by checkmate".
+// Returns an empty string if the game has not ended.
+func (ctx Context) ResultDescription() string {
+	var description string
+	switch ctx.Result {
+	case WhiteWin:
+		description = "White wins"
+	case BlackWin:
+		description = "Black wins"
+	case Draw:
+		description = "Draw"
+	default:
+		return ""
+	}
+
+	if ctx.Termination != NoTermination {
+		description += " by " + ctx.Termination.String()
+	}
+	return description
+}
+
+// removeCastlingRightsAt removes the castling rights of the rooks initial squares in mask.
+func (ctx *Context) removeCastlingRightsAt(mask uint64) {
+	if mask&H1 != 0 {
+		ctx.WhiteCastlingKingSide = false
+	}
+	if mask&A1 != 0 {
+		ctx.WhiteCastlingQueenSide = false
+	}
+	if mask&H8 != 0 {
+		ctx.BlackCastlingKingSide = false
+	}
+	if mask&A8 != 0 {
+		ctx.BlackCastlingQueenSide = false
+	}
 }
@@ -62,38 +62,6 @@ func moveDownRight(piecePos uint64, multiplier int) uint64 {
 	return moveDown(moveRight(piecePos, multiplier), multiplier)
 }
 
-func moveL1(piecePos uint64) uint64 {
-	return moveUp(moveLeft(piecePos, 1), 2)
-}
-
-func moveL2(piecePos uint64) uint64 {
-	return moveUp(moveRight(piecePos, 1), 2)
-}
-
-func moveL3(piecePos uint64) uint64 {
-	return moveDown(moveLeft(piecePos, 1), 2)
-}
-
-func moveL4(piecePos uint64) uint64 {
-	return moveDown(moveRight(piecePos, 1), 2)
-}
-
-func moveL5(piecePos uint64) uint64 {
-	return moveLeft(moveUp(piecePos, 1), 2)
-}
-
-func moveL6(piecePos uint64) uint64 {
-	return moveRight(moveUp(piecePos, 1), 2)
-}
-
-func moveL7(piecePos uint64) uint64 {
-	return moveLeft(moveDown(piecePos, 1), 2)
-}
-
-func moveL8(piecePos uint64) uint64 {
-	return moveRight(moveDown(piecePos, 1), 2)
-}
-
 func GetDirectionFunc(direction int) directionFunc {
 	switch direction {
 	case directionUp:
@@ -1,25 +1,110 @@
 package chess
 
 import (
+	"fmt"
+	"math/bits"
 	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/log"
 )
 
+// Index of each field of a FEN string
+const (
+	FenPlacementField = iota
+	FenTurnField
+	FenCastlingField
+	FenEnPassantField
+	FenHalfMovesField
+	FenMoveNumberField
+	fenFieldsCount
+)
+
+var fenFieldNames = [fenFieldsCount]string{"piece placement", "side to move", "castling", "en passant", "half move clock", "move number"}
+
+// FenError describes why a FEN string is invalid.
+type FenError struct {
+	Field  int    // Index of the invalid field, see FenPlacementField and the other constants. -1 if it's the whole string
+	Token  string // Offending token
+	Reason string
+}
+
+func (e *FenError) Error() string {
+	if e.Field < 0 || e.Field >= fenFieldsCount {
+		return fmt.Sprintf("Invalid FEN %q: %s", e.Token, e.Reason)
+	}
+	return fmt.Sprintf("Invalid FEN %s (field %d) %q: %s", fenFieldNames[e.Field], e.Field, e.Token, e.Reason)
+}
+
+func newFenError(field int, token, reason string, args ...any) *FenError {
+	return &FenError{Field: field, Token: token, Reason: fmt.Sprintf(reason, args...)}
+}
+
+// Fields used by FenToBoard when the FEN only has the piece placement
+const defaultFenContext = "w - - 0 1"
+
+// FenToBoard is like ParseFEN but exits the program if the FEN is invalid.
+// Use it only with FENs known to be valid. If the FEN only has the piece placement,
+// white moves first, without castling rights nor en passant.
 func FenToBoard(fen string) *Board {
+	if len(strings.Fields(fen)) == 1 {
+		fen += " " + defaultFenContext
+	}
+	b, err := ParseFEN(fen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return b
+}
+
+// ParseFEN parses and validates a FEN string.
+// The returned error is always a *FenError.
+func ParseFEN(fen string) (*Board, error) {
+	fields := strings.Fields(fen)
+	if len(fields) != fenFieldsCount {
+		return nil, newFenError(-1, fen, "expected %d fields, got %d", fenFieldsCount, len(fields))
+	}
+
 	b := NewBoard()
-	splitted := strings.Split(fen, " ")
-	pos := splitted[0]
-	rows := strings.Split(pos, "/")
+	if err := parseFenPlacement(b, fields[FenPlacementField]); err != nil {
+		return nil, err
+	}
+	ctx, err := ParseFENContext(fields[FenTurnField:])
+	if err != nil {
+		return nil, err
+	}
+	b.Ctx = ctx
+	if err := validateFenContext(b, fields); err != nil {
+		return nil, err
+	}
+	b.Ctx.hash = b.ComputeHash()
+	return b, nil
+}
+
+func parseFenPlacement(b *Board, placement string) error {
+	rows := strings.Split(placement, "/")
+	if len(rows) != 8 {
+		return newFenError(FenPlacementField, placement, "expected 8 ranks, got %d", len(rows))
+	}
+
 	row := 7
 	for _, rowString := range rows {
 		col := 0
+		lastWasDigit := false
 		for _, char := range rowString {
+			if col >= 8 {
+				return newFenError(FenPlacementField, rowString, "rank %d has more than 8 squares", row+1)
+			}
+
 			if char >= '1' && char <= '8' {
+				if lastWasDigit {
+					return newFenError(FenPlacementField, rowString, "consecutive digits in rank %d", row+1)
+				}
+				lastWasDigit = true
 				col += int(char - '0')
 				continue
 			}
+			lastWasDigit = false
 
 			isWhite := char >= 'A' && char <= 'Z'
 			pieceType := PieceTypeFromChar(char)
@@ -33,6 +118,9 @@ func FenToBoard(fen string) *Board {
 			var pp *PiecesPosition
 			switch pieceType {
 			case PawnType:
+				if row == 0 || row == 7 {
+					return newFenError(FenPlacementField, rowString, "pawn on rank %d", row+1)
+				}
 				pp = &pb.Pawns
 			case KnightType:
 				pp = &pb.Knights
@@ -45,68 +133,225 @@ func FenToBoard(fen string) *Board {
 			case KingType:
 				pp = &pb.King
 			default:
-				log.Fatalf("Invalid piece type: %v. Got from char: %c", pieceType, char)
+				return newFenError(FenPlacementField, rowString, "invalid piece %q", char)
 			}
 
 			pp.SetPieceAt(col, row)
 			col++
 		}
+		if col != 8 {
+			return newFenError(FenPlacementField, rowString, "rank %d has %d squares, expected 8", row+1, col)
+		}
 
 		row--
 	}
 
-	if len(splitted) > 1 {
-		b.Ctx = FenToContext(splitted[1:])
+	if count := bits.OnesCount64(b.White.King.Board); count != 1 {
+		return newFenError(FenPlacementField, placement, "expected 1 white king, got %d", count)
 	}
-
-	return b
+	if count := bits.OnesCount64(b.Black.King.Board); count != 1 {
+		return newFenError(FenPlacementField, placement, "expected 1 black king, got %d", count)
+	}
+	return nil
 }
 
+// FenToContext is like ParseFENContext but exits the program if the fields are invalid.
 func FenToContext(splitted []string) Context {
-	if len(splitted) != 5 {
-		log.Fatal("Invalid fen context")
+	ctx, err := ParseFENContext(splitted)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return ctx
+}
+
+// ParseFENContext parses the last 5 fields of a FEN string (everything except the piece placement).
+// The hash of the returned context is not set, as it depends on the pieces.
+// Field indexes in the returned *FenError are relative to the full FEN string.
+func ParseFENContext(splitted []string) (Context, error) {
+	if len(splitted) != fenFieldsCount-1 {
+		return Context{}, newFenError(-1, strings.Join(splitted, " "), "expected %d context fields, got %d", fenFieldsCount-1, len(splitted))
 	}
 
 	ctx := Context{}
-	if splitted[0] == "w" {
+	switch turn := splitted[FenTurnField-1]; turn {
+	case "w":
 		ctx.WhiteTurn = true
-	} else {
+	case "b":
 		ctx.WhiteTurn = false
+	default:
+		return Context{}, newFenError(FenTurnField, turn, "expected w or b")
 	}
 
-	castling := splitted[1]
-	if strings.Contains(castling, "K") {
-		ctx.WhiteCastlingKingSide = true
-	}
-	if strings.Contains(castling, "Q") {
-		ctx.WhiteCastlingQueenSide = true
-	}
-	if strings.Contains(castling, "k") {
-		ctx.BlackCastlingKingSide = true
-	}
-	if strings.Contains(castling, "q") {
-		ctx.BlackCastlingQueenSide = true
+	castling := splitted[FenCastlingField-1]
+	if castling != "-" {
+		for _, char := range castling {
+			var right *bool
+			switch char {
+			case 'K':
+				right = &ctx.WhiteCastlingKingSide
+			case 'Q':
+				right = &ctx.WhiteCastlingQueenSide
+			case 'k':
+				right = &ctx.BlackCastlingKingSide
+			case 'q':
+				right = &ctx.BlackCastlingQueenSide
+			default:
+				return Context{}, newFenError(FenCastlingField, castling, "invalid castling right %q", char)
+			}
+			if *right {
+				return Context{}, newFenError(FenCastlingField, castling, "repeated castling right %q", char)
+			}
+			*right = true
+		}
 	}
 
-	enPassantCoord := splitted[2]
+	enPassantCoord := splitted[FenEnPassantField-1]
 	if enPassantCoord != "-" {
+		if len(enPassantCoord) != 2 || enPassantCoord[0] < 'a' || enPassantCoord[0] > 'h' || enPassantCoord[1] < '1' || enPassantCoord[1] > '8' {
+			return Context{}, newFenError(FenEnPassantField, enPassantCoord, "invalid square")
+		}
 		col := int(enPassantCoord[0] - 'a')
 		row := int(enPassantCoord[1] - '1')
 		ctx.EnPassant = PositionToUInt64(col, row)
 	}
 
-	halfMoveClock := splitted[3]
-	HalfMovesInt, err := strconv.Atoi(halfMoveClock)
-	if err != nil {
-		log.Fatalf("Invalid half move clock: %v", halfMoveClock)
+	halfMoveClock := splitted[FenHalfMovesField-1]
+	halfMovesInt, err := strconv.Atoi(halfMoveClock)
+	if err != nil || halfMovesInt < 0 {
+		return Context{}, newFenError(FenHalfMovesField, halfMoveClock, "expected a non negative number")
 	}
-	moveNumber := splitted[4]
+	moveNumber := splitted[FenMoveNumberField-1]
 	moveNumberInt, err := strconv.Atoi(moveNumber)
-	if err != nil {
-		log.Fatalf("Invalid move number: %v", moveNumber)
+	if err != nil || moveNumberInt < 1 {
+		return Context{}, newFenError(FenMoveNumberField, moveNumber, "expected a positive number")
 	}
 
 	ctx.MoveNumber = uint(moveNumberInt)
-	ctx.HalfMoves = uint(HalfMovesInt)
-	return ctx
+	ctx.HalfMoves = uint(halfMovesInt)
+	return ctx, nil
+}
+
+// validateFenContext checks if the side to move, the castling rights and the en passant square
+// are consistent with the pieces.
+func validateFenContext(b *Board, fields []string) error {
+	// The side that just moved can't have left its king in check, the king could be captured
+	if !b.IsValidPosition() {
+		return newFenError(FenTurnField, fields[FenTurnField], "the side not to move is in check")
+	}
+
+	castling := fields[FenCastlingField]
+	whiteKingOnE1 := b.White.King.Board == E1
+	blackKingOnE8 := b.Black.King.Board == E8
+	if b.Ctx.WhiteCastlingKingSide && (!whiteKingOnE1 || b.White.Rooks.Board&H1 == 0) {
+		return newFenError(FenCastlingField, castling, "white can't castle king side without king on e1 and rook on h1")
+	}
+	if b.Ctx.WhiteCastlingQueenSide && (!whiteKingOnE1 || b.White.Rooks.Board&A1 == 0) {
+		return newFenError(FenCastlingField, castling, "white can't castle queen side without king on e1 and rook on a1")
+	}
+	if b.Ctx.BlackCastlingKingSide && (!blackKingOnE8 || b.Black.Rooks.Board&H8 == 0) {
+		return newFenError(FenCastlingField, castling, "black can't castle king side without king on e8 and rook on h8")
+	}
+	if b.Ctx.BlackCastlingQueenSide && (!blackKingOnE8 || b.Black.Rooks.Board&A8 == 0) {
+		return newFenError(FenCastlingField, castling, "black can't castle queen side without king on e8 and rook on a8")
+	}
+
+	if b.Ctx.EnPassant == 0 {
+		return nil
+	}
+	// The pawn that just moved 2 squares must be in front of the en passant square,
+	// and the en passant square and the one behind it must be empty
+	enPassant := fields[FenEnPassantField]
+	var pawnPos, originPos, expectedRow uint64
+	var enemyPawns uint64
+	if b.Ctx.WhiteTurn {
+		pawnPos = moveDown(b.Ctx.EnPassant, 1)
+		originPos = moveUp(b.Ctx.EnPassant, 1)
+		expectedRow = 0x0000FF0000000000 // Rank 6
+		enemyPawns = b.Black.Pawns.Board
+	} else {
+		pawnPos = moveUp(b.Ctx.EnPassant, 1)
+		originPos = moveDown(b.Ctx.EnPassant, 1)
+		expectedRow = 0x0000000000FF0000 // Rank 3
+		enemyPawns = b.White.Pawns.Board
+	}
+	if b.Ctx.EnPassant&expectedRow == 0 {
+		return newFenError(FenEnPassantField, enPassant, "en passant square must be on rank 3 if black is to move or rank 6 if white is to move")
+	}
+	if pawnPos&enemyPawns == 0 {
+		return newFenError(FenEnPassantField, enPassant, "there's no pawn that could have just moved 2 squares")
+	}
+	occupied := b.White.AllBoardMask() | b.Black.AllBoardMask()
+	if (b.Ctx.EnPassant|originPos)&occupied != 0 {
+		return newFenError(FenEnPassantField, enPassant, "en passant square and the square behind it must be empty")
+	}
+	return nil
+}
+
+// ToFEN returns the FEN string of the board, the inverse of FenToBoard.
+func (b Board) ToFEN() string {
+	var sb strings.Builder
+	vb := b.VisualBoard()
+	for row := 7; row >= 0; row-- {
+		emptyCount := 0
+		for col := 0; col < 8; col++ {
+			piece := vb.Board[row][col]
+			if piece.Type == InvalidType {
+				emptyCount++
+				continue
+			}
+
+			if emptyCount > 0 {
+				sb.WriteString(strconv.Itoa(emptyCount))
+				emptyCount = 0
+			}
+			char := piece.Type.String()
+			if piece.IsWhite {
+				char = strings.ToUpper(char)
+			}
+			sb.WriteString(char)
+		}
+		if emptyCount > 0 {
+			sb.WriteString(strconv.Itoa(emptyCount))
+		}
+		if row != 0 {
+			sb.WriteRune('/')
+		}
+	}
+
+	sb.WriteRune(' ')
+	sb.WriteString(b.Ctx.ToFEN())
+	return sb.String()
+}
+
+// ToFEN returns the context fields of a FEN string (side to move, castling rights,
+// en passant square, half moves and move number), the inverse of FenToContext.
+func (ctx Context) ToFEN() string {
+	turn := "b"
+	if ctx.WhiteTurn {
+		turn = "w"
+	}
+
+	castling := ""
+	if ctx.WhiteCastlingKingSide {
+		castling += "K"
+	}
+	if ctx.WhiteCastlingQueenSide {
+		castling += "Q"
+	}
+	if ctx.BlackCastlingKingSide {
+		castling += "k"
+	}
+	if ctx.BlackCastlingQueenSide {
+		castling += "q"
+	}
+	if castling == "" {
+		castling = "-"
+	}
+
+	enPassant := "-"
+	if ctx.EnPassant != 0 {
+		enPassant = PositionToString(ctx.EnPassant)
+	}
+
+	return fmt.Sprintf("%s %s %s %d %d", turn, castling, enPassant, ctx.HalfMoves, ctx.MoveNumber)
 }
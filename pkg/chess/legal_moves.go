@@ -0,0 +1,86 @@
+package chess
+
+import "math/bits"
+
+// GenerateLegalMoves appends the legal moves of the side to move to the list, in the same order as
+// GeneratePseudoLegalMoves. Instead of making each move to see if it leaves the king in check, the pieces
+// giving check, the pinned pieces and the squares attacked by the opponent are computed once.
+// See https://www.chessprogramming.org/Move_Generation#Legal
+func (b Board) GenerateLegalMoves(list *MoveList) {
+	ourPb, enemyPb := b.White, b.Black
+	if !b.Ctx.WhiteTurn {
+		ourPb, enemyPb = b.Black, b.White
+	}
+	if ourPb.King.Board == 0 {
+		b.GeneratePseudoLegalMoves(list) // Not a real position, there's no check to care about
+		return
+	}
+	kingSquare := bits.TrailingZeros64(ourPb.King.Board)
+	our, enemy := b.sideMasks()
+	occupied := our | enemy
+
+	r := &moveRestrictions{checkMask: ^uint64(0), kingSquare: kingSquare, legal: true}
+	checkers := enemyPb.AttackersTo(kingSquare, occupied, !b.Ctx.WhiteTurn)
+	// In double check only the king can move
+	if bits.OnesCount64(checkers) < 2 {
+		if checkers != 0 {
+			// Capture the checker or, if it's a slider, block it
+			r.checkMask = checkers | between[kingSquare][bits.TrailingZeros64(checkers)]
+		}
+		r.findPins(kingSquare, our, enemy, enemyPb)
+		b.generatePieceMoves(list, r)
+	}
+
+	// The king is removed from the board, otherwise it would block the attacks along the ray of a checking slider,
+	// and moving away along that ray would look safe
+	danger := enemyPb.Attacks(occupied&^ourPb.King.Board, !b.Ctx.WhiteTurn)
+	list.addTargets(kingSquare, KingAttacks(kingSquare)&^our&^danger, enemy)
+	if checkers == 0 {
+		b.generateSafeCastlingMoves(list, kingSquare, ourPb.Rooks.Board, occupied, danger)
+	}
+}
+
+// findPins looks for enemy sliders that would attack our king if there wasn't exactly one of our pieces in between.
+func (r *moveRestrictions) findPins(kingSquare int, our, enemy uint64, enemyPb PartialBoard) {
+	// Sliders that attack the king through our pieces
+	snipers := RookAttacks(kingSquare, enemy)&(enemyPb.Rooks.Board|enemyPb.Queens.Board) |
+		BishopAttacks(kingSquare, enemy)&(enemyPb.Bishops.Board|enemyPb.Queens.Board)
+	for ; snipers != 0; snipers &= snipers - 1 {
+		sniperSquare := bits.TrailingZeros64(snipers)
+		blockers := between[kingSquare][sniperSquare] & (our | enemy)
+		if bits.OnesCount64(blockers) == 1 && blockers&our != 0 {
+			r.pinned |= blockers
+			r.pinRays[bits.TrailingZeros64(blockers)] = between[kingSquare][sniperSquare] | 1<<sniperSquare
+		}
+	}
+}
+
+// isEnPassantLegal returns true if capturing en passant doesn't leave the king in check. Two pawns leave
+// the board at the same time, so the capture can reveal an attack even if none of them is pinned,
+// e.g. a rook on the same rank as the king and both pawns.
+// The en passant capture also resolves a check given by the captured pawn.
+func (b Board) isEnPassantLegal(from, to, kingSquare int) bool {
+	enemyPb := b.Black
+	capturedSquare := to - 8
+	if !b.Ctx.WhiteTurn {
+		enemyPb = b.White
+		capturedSquare = to + 8
+	}
+	our, enemy := b.sideMasks()
+	occupied := (our|enemy)&^(1<<from|1<<capturedSquare) | 1<<to
+	enemyPb.Pawns.Board &^= 1 << capturedSquare
+	return enemyPb.AttackersTo(kingSquare, occupied, !b.Ctx.WhiteTurn) == 0
+}
+
+// generateSafeCastlingMoves appends the castling moves where the king doesn't cross an attacked square.
+// The king must not be in check.
+func (b Board) generateSafeCastlingMoves(list *MoveList, kingSquare int, rooks, occupied, danger uint64) {
+	var castling MoveList
+	b.generateCastlingMoves(&castling, kingSquare, rooks, occupied)
+	for _, move := range castling.Moves() {
+		// The king crosses the squares between its origin and destination, and the destination itself
+		if (between[move.From()][move.To()]|1<<move.To())&danger == 0 {
+			list.Add(move)
+		}
+	}
+}
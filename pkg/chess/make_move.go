@@ -22,6 +22,9 @@ func (b *Board) MakePseudoLegalMove(m Move) {
 	b.MovesDone = append(b.MovesDone, m)
 	b.PreviousCtx = append(b.PreviousCtx, b.Ctx)
 
+	// Removes the old castling and en passant keys from the hash, the new ones are added at the end
+	hash := b.Ctx.hash ^ castlingHash(b.Ctx) ^ b.enPassantHash()
+
 	if m.IsCastling { // Castling verifications
 		isKingSide := m.NewPiecePos < m.OldPiecePos
 		// Move rook, king is moved on normal MakeMove
@@ -29,11 +32,13 @@ func (b *Board) MakePseudoLegalMove(m Move) {
 			if b.Ctx.WhiteTurn {
 				b.White.Rooks.Board &= ^H1
 				b.White.Rooks.Board |= F1
+				hash ^= pieceHash(true, RookType, H1) ^ pieceHash(true, RookType, F1)
 				b.Ctx.WhiteCastlingKingSide = false
 				b.Ctx.WhiteCastlingQueenSide = false
 			} else {
 				b.Black.Rooks.Board &= ^H8
 				b.Black.Rooks.Board |= F8
+				hash ^= pieceHash(false, RookType, H8) ^ pieceHash(false, RookType, F8)
 				b.Ctx.BlackCastlingKingSide = false
 				b.Ctx.BlackCastlingQueenSide = false
 			}
@@ -41,42 +46,35 @@ func (b *Board) MakePseudoLegalMove(m Move) {
 			if b.Ctx.WhiteTurn {
 				b.White.Rooks.Board &= ^A1
 				b.White.Rooks.Board |= D1
+				hash ^= pieceHash(true, RookType, A1) ^ pieceHash(true, RookType, D1)
 				b.Ctx.WhiteCastlingKingSide = false
 				b.Ctx.WhiteCastlingQueenSide = false
 			} else {
 				b.Black.Rooks.Board &= ^A8
 				b.Black.Rooks.Board |= D8
+				hash ^= pieceHash(false, RookType, A8) ^ pieceHash(false, RookType, D8)
 				b.Ctx.BlackCastlingKingSide = false
 				b.Ctx.BlackCastlingQueenSide = false
 			}
 		}
 	}
-	// Removes castling rights if the piece is a rook or king
-	if m.PieceType == RookType || m.PieceType == KingType {
+	// Removes castling rights if the king moves, or if a rook leaves or is captured on its initial square
+	if m.PieceType == KingType {
 		if b.Ctx.WhiteTurn {
-			if m.OldPiecePos == A1 {
-				b.Ctx.WhiteCastlingQueenSide = false
-			} else if m.OldPiecePos == H1 {
-				b.Ctx.WhiteCastlingKingSide = false
-			} else {
-				b.Ctx.WhiteCastlingKingSide = false
-				b.Ctx.WhiteCastlingQueenSide = false
-			}
+			b.Ctx.WhiteCastlingKingSide = false
+			b.Ctx.WhiteCastlingQueenSide = false
 		} else {
-			if m.OldPiecePos == A8 {
-				b.Ctx.BlackCastlingQueenSide = false
-			} else if m.OldPiecePos == H8 {
-				b.Ctx.BlackCastlingKingSide = false
-			} else {
-				b.Ctx.BlackCastlingKingSide = false
-				b.Ctx.BlackCastlingQueenSide = false
-			}
+			b.Ctx.BlackCastlingKingSide = false
+			b.Ctx.BlackCastlingQueenSide = false
 		}
 	}
+	b.Ctx.removeCastlingRightsAt(m.OldPiecePos | m.NewPiecePos)
 	if m.IsPromotion {
 		pb.MakePromotion(m)
+		hash ^= pieceHash(b.Ctx.WhiteTurn, PawnType, m.OldPiecePos) ^ pieceHash(b.Ctx.WhiteTurn, m.NewPieceType, m.NewPiecePos)
 	} else {
 		pb.MakeMove(m)
+		hash ^= pieceHash(b.Ctx.WhiteTurn, m.PieceType, m.OldPiecePos) ^ pieceHash(b.Ctx.WhiteTurn, m.PieceType, m.NewPiecePos)
 	}
 	// Removes enemy piece if it's a capture
 	if m.IsCapture {
@@ -103,6 +101,7 @@ func (b *Board) MakePseudoLegalMove(m Move) {
 				}
 			}
 			pb.Pawns.Board &= ^enemyPawnPos
+			hash ^= pieceHash(!b.Ctx.WhiteTurn, PawnType, enemyPawnPos)
 		case KnightType:
 			pb.Knights.Board &= ^m.NewPiecePos
 		case BishopType:
@@ -114,6 +113,9 @@ func (b *Board) MakePseudoLegalMove(m Move) {
 		default:
 			log.Fatalf("Invalid piece type: %v", m.CapturedPieceType)
 		}
+		if m.CapturedPieceType != PawnType {
+			hash ^= pieceHash(!b.Ctx.WhiteTurn, m.CapturedPieceType, m.NewPiecePos)
+		}
 	}
 
 	// Check for next move En passant
@@ -141,6 +143,7 @@ func (b *Board) MakePseudoLegalMove(m Move) {
 	}
 	b.Ctx.WhiteTurn = !b.Ctx.WhiteTurn
 	b.Ctx.EnPassant = enPassantPos
+	b.Ctx.hash = hash ^ zobristWhiteTurn ^ castlingHash(b.Ctx) ^ b.enPassantHash()
 
 	// Reset cached values to false, because it's a new position
 	b.Ctx.IsKingInCheckCacheSet = false
@@ -148,12 +151,35 @@ func (b *Board) MakePseudoLegalMove(m Move) {
 	b.Ctx.IsDrawCacheSet = false
 }
 
+// MakeNullMove passes the turn to the opponent without moving any piece, it's used by the search
+// to find out if the position is so good that it's still good after giving a free move.
+// It's stored in MovesDone as an empty move (see Move.IsNullMove) and undone by UndoMove.
+// It must not be done when the king is in check.
+func (b *Board) MakeNullMove() {
+	b.MovesDone = append(b.MovesDone, Move{})
+	b.PreviousCtx = append(b.PreviousCtx, b.Ctx)
+
+	// The en passant key depends on the side to move, so it's removed before changing it
+	hash := b.Ctx.hash ^ b.enPassantHash() ^ zobristWhiteTurn
+	if !b.Ctx.WhiteTurn {
+		b.Ctx.MoveNumber++
+	}
+	b.Ctx.HalfMoves++
+	b.Ctx.WhiteTurn = !b.Ctx.WhiteTurn
+	b.Ctx.EnPassant = 0
+	b.Ctx.hash = hash
+	b.Ctx.ContextCache = ContextCache{}
+}
+
 func (b *Board) UndoMove() {
 	// Get the last move and context
 	lastMove := b.MovesDone[len(b.MovesDone)-1]
 	b.MovesDone = b.MovesDone[:len(b.MovesDone)-1]       // Pop
-	b.Ctx = b.PreviousCtx[len(b.PreviousCtx)-1]          // Restores the context
+	b.Ctx = b.PreviousCtx[len(b.PreviousCtx)-1]          // Restores the context, including the hash
 	b.PreviousCtx = b.PreviousCtx[:len(b.PreviousCtx)-1] // Pop
+	if lastMove.IsNullMove() {
+		return // No piece was moved
+	}
 
 	var ourPb, enemyPb *PartialBoard
 	if b.Ctx.WhiteTurn {
@@ -165,27 +191,14 @@ func (b *Board) UndoMove() {
 	}
 
 	// Restore the piece to the previous position
-	switch lastMove.PieceType {
-	case PawnType:
-		ourPb.Pawns.Board &= ^lastMove.NewPiecePos
+	if lastMove.IsPromotion {
+		// Removes the promoted piece and restores the pawn
+		ourPb.piecesByType(lastMove.NewPieceType).Board &= ^lastMove.NewPiecePos
 		ourPb.Pawns.Board |= lastMove.OldPiecePos
-	case KnightType:
-		ourPb.Knights.Board &= ^lastMove.NewPiecePos
-		ourPb.Knights.Board |= lastMove.OldPiecePos
-	case BishopType:
-		ourPb.Bishops.Board &= ^lastMove.NewPiecePos
-		ourPb.Bishops.Board |= lastMove.OldPiecePos
-	case RookType:
-		ourPb.Rooks.Board &= ^lastMove.NewPiecePos
-		ourPb.Rooks.Board |= lastMove.OldPiecePos
-	case QueenType:
-		ourPb.Queens.Board &= ^lastMove.NewPiecePos
-		ourPb.Queens.Board |= lastMove.OldPiecePos
-	case KingType:
-		ourPb.King.Board &= ^lastMove.NewPiecePos
-		ourPb.King.Board |= lastMove.OldPiecePos
-	default:
-		log.Fatalf("Invalid piece type: %v", lastMove.PieceType)
+	} else {
+		pp := ourPb.piecesByType(lastMove.PieceType)
+		pp.Board &= ^lastMove.NewPiecePos
+		pp.Board |= lastMove.OldPiecePos
 	}
 
 	// Restore the captured piece if it's a capture
@@ -193,11 +206,12 @@ func (b *Board) UndoMove() {
 		switch lastMove.CapturedPieceType {
 		case PawnType:
 			pos := lastMove.NewPiecePos
+			// The pawn captured en passant was behind the destination square, from the point of view of the capturer
 			if lastMove.IsEnPassant {
 				if b.Ctx.WhiteTurn {
-					pos = moveUp(pos, 1) // White pawn
-				} else {
 					pos = moveDown(pos, 1) // Black pawn
+				} else {
+					pos = moveUp(pos, 1) // White pawn
 				}
 			}
 			enemyPb.Pawns.Board |= pos
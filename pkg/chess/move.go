@@ -15,7 +15,7 @@ type Move struct {
 	NewPieceType      PieceType
 	CapturedPieceType PieceType
 	IsCheckFieldSet   bool
-	isLegal           bool // Setted by AllLegalMoves
+	isLegal           bool // Setted by AllLegalMoves and UnpackLegalMove
 }
 
 func (m Move) Is2SquarePawnMove() bool {
@@ -25,19 +25,22 @@ func (m Move) Is2SquarePawnMove() bool {
 	return (m.OldPiecePos>>16 == m.NewPiecePos) || (m.OldPiecePos<<16 == m.NewPiecePos)
 }
 
+// IsNullMove returns true if the move is the empty move done by Board.MakeNullMove.
+func (m Move) IsNullMove() bool {
+	return m.OldPiecePos == 0
+}
+
 func (m Move) String() string {
 	return fmt.Sprintf("Move{OldPiecePos: %d, NewPiecePos: %d, IsCastling: %t, IsCapture: %t, IsPromotion: %t, IsCheck: %t, PieceType: %s, NewPieceType: %s, CapturedPieceType: %s}",
 		m.OldPiecePos, m.NewPiecePos, m.IsCastling, m.IsCapture, m.IsPromotion, m.IsCheck, m.PieceType.String(), m.NewPieceType.String(), m.CapturedPieceType.String())
 }
 
 func (m Move) StockfishString() string {
-	pos := Int64toPositions(m.OldPiecePos)[0]
-	col, row := pos[0], pos[1]
-	colLetter := "abcdefgh"[col]
-	oldPos := fmt.Sprintf("%c%d", colLetter, row+1)
-	pos = Int64toPositions(m.NewPiecePos)[0]
-	col, row = pos[0], pos[1]
-	colLetter = "abcdefgh"[col]
-	newPos := fmt.Sprintf("%c%d", colLetter, row+1)
+	oldPos := PositionToString(m.OldPiecePos)
+	newPos := PositionToString(m.NewPiecePos)
+	if m.IsPromotion {
+		// Promotion piece is always lowercase, e.g. e7e8q
+		newPos += m.NewPieceType.String()
+	}
 	return fmt.Sprintf("%s%s", oldPos, newPos)
 }
@@ -0,0 +1,194 @@
+package chess
+
+import "math/bits"
+
+// MaxMoves is more than the maximum number of moves in a legal position (218).
+const MaxMoves = 256
+
+// MoveList is a fixed-capacity list of packed moves, so the generators don't allocate memory.
+// It's meant to be declared as a local variable and passed by pointer.
+type MoveList struct {
+	moves [MaxMoves]PackedMove
+	count int
+}
+
+// Add appends a move to the list.
+func (ml *MoveList) Add(move PackedMove) {
+	ml.moves[ml.count] = move
+	ml.count++
+}
+
+func (ml *MoveList) Len() int {
+	return ml.count
+}
+
+// At returns the move at index i, which must be lower than Len.
+func (ml *MoveList) At(i int) PackedMove {
+	return ml.moves[i]
+}
+
+// Moves returns the moves of the list. The slice shares the memory of the list.
+func (ml *MoveList) Moves() []PackedMove {
+	return ml.moves[:ml.count]
+}
+
+// Clear removes all the moves, so the list can be reused.
+func (ml *MoveList) Clear() {
+	ml.count = 0
+}
+
+// addTargets adds the moves from the square to each target, which must not have our pieces.
+func (ml *MoveList) addTargets(from int, targets, enemy uint64) {
+	for targets != 0 {
+		to := bits.TrailingZeros64(targets)
+		targets &= targets - 1 // Removes the LSB
+		flags := QuietMove
+		if enemy&(1<<to) != 0 {
+			flags = CaptureMove
+		}
+		ml.Add(NewPackedMove(from, to, flags))
+	}
+}
+
+// addPromotions adds the 4 promotions of a pawn move, the queen first.
+func (ml *MoveList) addPromotions(from, to int, isCapture bool) {
+	for _, pieceType := range []PieceType{QueenType, RookType, BishopType, KnightType} {
+		ml.Add(NewPackedMove(from, to, promotionFlags(pieceType, isCapture)))
+	}
+}
+
+// GeneratePseudoLegalMoves appends the moves of the side to move to the list, without checking if they
+// leave the king in check. Castling moves only check the rights and the empty squares between the king and the rook.
+// The moves are in the same order as the Move generators: pawns, knights, bishops, rooks, queens, king and castling.
+func (b Board) GeneratePseudoLegalMoves(list *MoveList) {
+	b.generatePieceMoves(list, &moveRestrictions{checkMask: ^uint64(0)})
+	ourPb := b.White
+	if !b.Ctx.WhiteTurn {
+		ourPb = b.Black
+	}
+	if ourPb.King.Board != 0 {
+		from := bits.TrailingZeros64(ourPb.King.Board)
+		our, enemy := b.sideMasks()
+		list.addTargets(from, KingAttacks(from)&^our, enemy)
+		b.generateCastlingMoves(list, from, ourPb.Rooks.Board, our|enemy)
+	}
+}
+
+// sideMasks returns the squares with pieces of the side to move and of the opponent.
+func (b Board) sideMasks() (our, enemy uint64) {
+	if b.Ctx.WhiteTurn {
+		return b.White.AllBoardMask(), b.Black.AllBoardMask()
+	}
+	return b.Black.AllBoardMask(), b.White.AllBoardMask()
+}
+
+// moveRestrictions limits where the pieces other than the king can move, so only legal moves are generated.
+type moveRestrictions struct {
+	checkMask  uint64     // Squares that capture or block the piece giving check, all of them if not in check
+	pinned     uint64     // Our pieces that can't leave the ray between our king and an enemy slider
+	pinRays    [64]uint64 // Squares where each pinned piece can move, including the capture of the pinner
+	kingSquare int
+	legal      bool // Checks that capturing en passant doesn't leave the king in check
+}
+
+// allowed returns the squares where the piece on the square can move.
+func (r *moveRestrictions) allowed(from int) uint64 {
+	if r.pinned&(1<<from) != 0 {
+		return r.checkMask & r.pinRays[from]
+	}
+	return r.checkMask
+}
+
+// generatePieceMoves appends the moves of all the pieces of the side to move but the king.
+func (b Board) generatePieceMoves(list *MoveList, r *moveRestrictions) {
+	ourPb := b.White
+	if !b.Ctx.WhiteTurn {
+		ourPb = b.Black
+	}
+	our, enemy := b.sideMasks()
+	occupied := our | enemy
+
+	for pieces := ourPb.Pawns.Board; pieces != 0; pieces &= pieces - 1 {
+		b.generatePawnMoves(list, bits.TrailingZeros64(pieces), enemy, occupied, r)
+	}
+	for pieces := ourPb.Knights.Board; pieces != 0; pieces &= pieces - 1 {
+		from := bits.TrailingZeros64(pieces)
+		list.addTargets(from, KnightAttacks(from)&^our&r.allowed(from), enemy)
+	}
+	for pieces := ourPb.Bishops.Board; pieces != 0; pieces &= pieces - 1 {
+		from := bits.TrailingZeros64(pieces)
+		list.addTargets(from, BishopAttacks(from, occupied)&^our&r.allowed(from), enemy)
+	}
+	for pieces := ourPb.Rooks.Board; pieces != 0; pieces &= pieces - 1 {
+		from := bits.TrailingZeros64(pieces)
+		list.addTargets(from, RookAttacks(from, occupied)&^our&r.allowed(from), enemy)
+	}
+	for pieces := ourPb.Queens.Board; pieces != 0; pieces &= pieces - 1 {
+		from := bits.TrailingZeros64(pieces)
+		list.addTargets(from, QueenAttacks(from, occupied)&^our&r.allowed(from), enemy)
+	}
+}
+
+func (b Board) generatePawnMoves(list *MoveList, from int, enemy, occupied uint64, r *moveRestrictions) {
+	forward, promotionRank, initialRank := 8, uint64(0xFF)<<56, uint64(0xFF)<<8
+	if !b.Ctx.WhiteTurn {
+		forward, promotionRank, initialRank = -8, uint64(0xFF), uint64(0xFF)<<48
+	}
+	allowed := r.allowed(from)
+
+	to := from + forward
+	if occupied&(1<<to) == 0 {
+		if allowed&(1<<to) != 0 {
+			if promotionRank&(1<<to) != 0 {
+				list.addPromotions(from, to, false)
+			} else {
+				list.Add(NewPackedMove(from, to, QuietMove))
+			}
+		}
+		doublePush := to + forward
+		if initialRank&(1<<from) != 0 && occupied&(1<<doublePush) == 0 && allowed&(1<<doublePush) != 0 {
+			list.Add(NewPackedMove(from, doublePush, DoublePawnPush))
+		}
+	}
+
+	attacks := PawnAttacks(from, b.Ctx.WhiteTurn)
+	for captures := attacks & (enemy&allowed | b.Ctx.EnPassant); captures != 0; captures &= captures - 1 {
+		to := bits.TrailingZeros64(captures)
+		switch {
+		case b.Ctx.EnPassant&(1<<to) != 0:
+			if !r.legal || b.isEnPassantLegal(from, to, r.kingSquare) {
+				list.Add(NewPackedMove(from, to, EnPassantCapture))
+			}
+		case promotionRank&(1<<to) != 0:
+			list.addPromotions(from, to, true)
+		default:
+			list.Add(NewPackedMove(from, to, CaptureMove))
+		}
+	}
+}
+
+// Castling squares of white, black ones are 56 bits up
+const (
+	kingSideCastleSpace   = uint64(0b0000_0110) // F1 and G1
+	queenSideCastleSpace  = uint64(0b0111_0000) // B1, C1 and D1
+	kingSideCastleTarget  = 1                   // G1
+	queenSideCastleTarget = 5                   // C1
+)
+
+func (b Board) generateCastlingMoves(list *MoveList, kingSquare int, rooks, occupied uint64) {
+	canCastleKingSide, canCastleQueenSide := b.Ctx.WhiteCastlingKingSide, b.Ctx.WhiteCastlingQueenSide
+	kingSideRook, queenSideRook := H1, A1
+	shift := 0
+	if !b.Ctx.WhiteTurn {
+		canCastleKingSide, canCastleQueenSide = b.Ctx.BlackCastlingKingSide, b.Ctx.BlackCastlingQueenSide
+		kingSideRook, queenSideRook = H8, A8
+		shift = 56
+	}
+
+	if canCastleKingSide && rooks&kingSideRook != 0 && occupied&(kingSideCastleSpace<<shift) == 0 {
+		list.Add(NewPackedMove(kingSquare, kingSideCastleTarget+shift, KingCastle))
+	}
+	if canCastleQueenSide && rooks&queenSideRook != 0 && occupied&(queenSideCastleSpace<<shift) == 0 {
+		list.Add(NewPackedMove(kingSquare, queenSideCastleTarget+shift, QueenCastle))
+	}
+}
@@ -1,14 +1,16 @@
 package chess
 
 import (
+	"math/bits"
+
 	"github.com/charmbracelet/log"
 )
 
 // MovesFunction is a function that returns all possible new positions for a piece position in the complete Board.
 type MovesFunction func(Board, uint64) []Move
 
-func normalMoves(board Board, pieceBoard uint64, directions []int, pieceType PieceType) []Move {
-	moves := make([]Move, 0, len(directions)*7)
+// targetMoves returns the moves of the piece to the target squares that aren't occupied by our pieces.
+func targetMoves(board Board, pieceBoard, targets uint64, pieceType PieceType) []Move {
 	var ourPb PartialBoard
 	var enemyPb PartialBoard
 	if board.Ctx.WhiteTurn {
@@ -18,80 +20,33 @@ func normalMoves(board Board, pieceBoard uint64, directions []int, pieceType Pie
 		ourPb = board.Black
 		enemyPb = board.White
 	}
-	for _, direction := range directions {
-		fn := GetDirectionFunc(direction)
-		for i := 1; i < 8; i++ {
-			if fn == nil {
-				log.Fatal("Invalid direction")
-			}
+	targets &= ^ourPb.AllBoardMask()
+	enemyMask := enemyPb.AllBoardMask()
 
-			newPieceBoard := fn(pieceBoard, i)
-			if newPieceBoard == 0 {
-				break
-			}
-
-			// check for collision
-			allColorBoard := ourPb.AllBoardMask() & ^pieceBoard // Removes the piece from the board
-			if newPieceBoard&allColorBoard != 0 {
-				break
-			}
+	moves := make([]Move, 0, bits.OnesCount64(targets))
+	for targets != 0 {
+		newPieceBoard := targets & -targets
+		targets &= targets - 1 // Removes the LSB
 
-			isCapture := newPieceBoard&enemyPb.AllBoardMask() != 0
-			capturedPiece := InvalidType
-			if isCapture {
-				capturedPiece = enemyPb.GetPieceTypeByPos(newPieceBoard)
-			}
-
-			move := Move{
-				OldPiecePos:       pieceBoard,
-				NewPiecePos:       newPieceBoard,
-				IsCapture:         isCapture,
-				CapturedPieceType: capturedPiece,
-				PieceType:         pieceType,
-			}
-			moves = append(moves, move)
-			// Capture check
-			if isCapture {
-				break
-			}
+		isCapture := newPieceBoard&enemyMask != 0
+		capturedPiece := InvalidType
+		if isCapture {
+			capturedPiece = enemyPb.GetPieceTypeByPos(newPieceBoard)
 		}
+		moves = append(moves, Move{
+			OldPiecePos:       pieceBoard,
+			NewPiecePos:       newPieceBoard,
+			IsCapture:         isCapture,
+			CapturedPieceType: capturedPiece,
+			PieceType:         pieceType,
+		})
 	}
-
 	return moves
 }
 
-func knightMove(board Board, pieceBoard uint64, fn func(uint64) uint64) []Move {
-	moves := make([]Move, 0, 1)
-	var ourPb PartialBoard
-	var enemyPb PartialBoard
-	if board.Ctx.WhiteTurn {
-		ourPb = board.White
-		enemyPb = board.Black
-	} else {
-		ourPb = board.Black
-		enemyPb = board.White
-	}
-
-	newPieceBoard := fn(pieceBoard)
-	if newPieceBoard != 0 {
-		allColorBoard := ourPb.AllBoardMask() & ^pieceBoard // Removes the piece from the board
-		if newPieceBoard&allColorBoard == 0 {
-			isCapture := newPieceBoard&enemyPb.AllBoardMask() != 0
-			capturedPiece := InvalidType
-			if isCapture {
-				capturedPiece = enemyPb.GetPieceTypeByPos(newPieceBoard)
-			}
-			move := Move{
-				OldPiecePos:       pieceBoard,
-				NewPiecePos:       newPieceBoard,
-				IsCapture:         isCapture,
-				CapturedPieceType: capturedPiece,
-				PieceType:         KnightType,
-			}
-			moves = append(moves, move)
-		}
-	}
-	return moves
+// occupied returns the squares with a piece of any color.
+func (b *Board) occupied() uint64 {
+	return b.White.AllBoardMask() | b.Black.AllBoardMask()
 }
 
 // Includes En Passant and promotion
@@ -165,13 +120,10 @@ func PawnMoves(board Board, pieceBoard uint64) []Move {
 	}
 
 	// Check capture moves
-	captureLeft := dirFn(moveLeft(pieceBoard, 1), 1)
-	captureRight := dirFn(moveRight(pieceBoard, 1), 1)
-	capturePoss := []uint64{captureLeft, captureRight}
-	for _, capturePos := range capturePoss {
-		if capturePos&enemyMask == 0 && capturePos&board.Ctx.EnPassant == 0 {
-			continue
-		}
+	captures := PawnAttacks(bits.TrailingZeros64(pieceBoard), board.Ctx.WhiteTurn) & (enemyMask | board.Ctx.EnPassant)
+	for captures != 0 {
+		capturePos := captures & -captures
+		captures &= captures - 1 // Removes the LSB
 
 		IsEnPassant := capturePos&board.Ctx.EnPassant != 0
 		isPromotion := isInPromotionRow(capturePos)
@@ -202,79 +154,24 @@ func PawnMoves(board Board, pieceBoard uint64) []Move {
 }
 
 func KnightMoves(board Board, pieceBoard uint64) []Move {
-	moves := make([]Move, 0, 8)
-	moves = append(moves, knightMove(board, pieceBoard, moveL1)...)
-	moves = append(moves, knightMove(board, pieceBoard, moveL2)...)
-	moves = append(moves, knightMove(board, pieceBoard, moveL3)...)
-	moves = append(moves, knightMove(board, pieceBoard, moveL4)...)
-	moves = append(moves, knightMove(board, pieceBoard, moveL5)...)
-	moves = append(moves, knightMove(board, pieceBoard, moveL6)...)
-	moves = append(moves, knightMove(board, pieceBoard, moveL7)...)
-	moves = append(moves, knightMove(board, pieceBoard, moveL8)...)
-
-	return moves
+	return targetMoves(board, pieceBoard, KnightAttacks(bits.TrailingZeros64(pieceBoard)), KnightType)
 }
 
 func BishopMoves(board Board, pieceBoard uint64) []Move {
-	directions := []int{directionUpLeft, directionUpRight, directionDownLeft, directionDownRight}
-	return normalMoves(board, pieceBoard, directions, BishopType)
+	attacks := BishopAttacks(bits.TrailingZeros64(pieceBoard), board.occupied())
+	return targetMoves(board, pieceBoard, attacks, BishopType)
 }
 
 func RookMoves(board Board, pieceBoard uint64) []Move {
-	directions := []int{directionUp, directionDown, directionLeft, directionRight}
-	return normalMoves(board, pieceBoard, directions, RookType)
+	attacks := RookAttacks(bits.TrailingZeros64(pieceBoard), board.occupied())
+	return targetMoves(board, pieceBoard, attacks, RookType)
 }
 
 func QueenMoves(board Board, pieceBoard uint64) []Move {
-	directions := []int{directionUp, directionDown, directionLeft, directionRight, directionUpLeft, directionUpRight, directionDownLeft, directionDownRight}
-	return normalMoves(board, pieceBoard, directions, QueenType)
+	attacks := QueenAttacks(bits.TrailingZeros64(pieceBoard), board.occupied())
+	return targetMoves(board, pieceBoard, attacks, QueenType)
 }
 
 func KingMoves(board Board, pieceBoard uint64) []Move {
-	moves := make([]Move, 0, 8)
-
-	var ourPb PartialBoard
-	var enemyPb PartialBoard
-	if board.Ctx.WhiteTurn {
-		ourPb = board.White
-		enemyPb = board.Black
-	} else {
-		ourPb = board.Black
-		enemyPb = board.White
-	}
-	directions := []int{directionUp, directionDown, directionLeft, directionRight, directionUpLeft, directionUpRight, directionDownLeft, directionDownRight}
-	for _, direction := range directions {
-		fn := GetDirectionFunc(direction)
-		if fn == nil {
-			log.Fatal("Invalid direction")
-		}
-
-		newPieceBoard := fn(pieceBoard, 1)
-		if newPieceBoard == 0 {
-			continue
-		}
-
-		// check for collision
-		allColorBoard := ourPb.AllBoardMask() & ^pieceBoard // Removes the piece from the board
-		if newPieceBoard&allColorBoard != 0 {
-			continue
-		}
-
-		isCapture := newPieceBoard&enemyPb.AllBoardMask() != 0
-		capturedPiece := InvalidType
-		if isCapture {
-			capturedPiece = enemyPb.GetPieceTypeByPos(newPieceBoard)
-		}
-
-		move := Move{
-			OldPiecePos:       pieceBoard,
-			NewPiecePos:       newPieceBoard,
-			IsCapture:         isCapture,
-			CapturedPieceType: capturedPiece,
-			PieceType:         KingType,
-		}
-		moves = append(moves, move)
-	}
-
-	return moves
+	return targetMoves(board, pieceBoard, KingAttacks(bits.TrailingZeros64(pieceBoard)), KingType)
 }
@@ -177,6 +177,17 @@ func (b Board) ParseMove(notation string) (Move, error) {
 	return move, nil
 }
 
+// ParseStockfishMove parses a move in the format returned by Move.StockfishString (e.g. e2e4, e7e8q).
+// Only legal moves are returned.
+func (b Board) ParseStockfishMove(s string) (Move, error) {
+	for _, move := range b.AllLegalMoves() {
+		if move.StockfishString() == s {
+			return move, nil
+		}
+	}
+	return Move{}, errors.New(fmt.Sprintf("Invalid move: %v", s))
+}
+
 func (b Board) MoveToNotation(move Move) string {
 	if move.IsCastling {
 		castle := "O-O"
@@ -271,7 +282,7 @@ func (b Board) MoveToNotation(move Move) string {
 	notation += string(rune('1' + destRow))
 
 	if move.IsPromotion {
-		notation := "="
+		notation += "="
 		switch move.NewPieceType {
 		case QueenType:
 			notation += "Q"
@@ -296,31 +307,39 @@ func (b Board) MoveToNotation(move Move) string {
 	return notation
 }
 
+// getMoveListInNotation returns the moves done in the game, replaying them from the first position,
+// so ambiguities, checks and checkmates are written correctly.
 func (b Board) getMoveListInNotation() string {
-	s := ""
+	board := b.startBoard()
+	var sb strings.Builder
 	for i, move := range b.MovesDone {
-		if i%2 == 0 {
-			s += fmt.Sprintf("%d. ", i/2+1)
+		if board.Ctx.WhiteTurn {
+			sb.WriteString(fmt.Sprintf("%d. ", board.Ctx.MoveNumber))
+		} else if i == 0 {
+			sb.WriteString(fmt.Sprintf("%d... ", board.Ctx.MoveNumber))
 		}
-		s += b.MoveToNotation(move) + " "
-	}
-	if b.IsMated() {
-		s = strings.TrimSpace(s)
-		s += "#"
+
+		notation := strings.TrimSuffix(board.MoveToNotation(move), "+")
+		board.MakePseudoLegalMove(move)
+		if board.IsMated() {
+			notation += "#"
+		} else if board.IsKingInCheck() {
+			notation += "+"
+		}
+		sb.WriteString(notation + " ")
 	}
-	return s
+	return sb.String()
 }
 
+// GetMoveListInNotation returns the moves done in the game followed by the result (if the game ended),
+// e.g. "1. f3 e5 2. g4 Qh4# {Black wins by checkmate} 0-1"
 func (b Board) GetMoveListInNotation() string {
 	moveList := b.getMoveListInNotation()
 	moveList = strings.TrimSpace(moveList)
-	switch b.Ctx.Result {
-	case WhiteWin:
-		moveList += " 1-0"
-	case BlackWin:
-		moveList += " 0-1"
-	case Draw:
-		moveList += " 1/2-1/2"
+	ctx := b.Ctx
+	ctx.Result, ctx.Termination = b.currentResult()
+	if ctx.Result != NoResult {
+		moveList += " {" + ctx.ResultDescription() + "} " + ctx.Result.String()
 	}
-	return moveList
+	return strings.TrimSpace(moveList)
 }
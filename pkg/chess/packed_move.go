@@ -0,0 +1,149 @@
+package chess
+
+import "math/bits"
+
+// PackedMove is a move in 16 bits: origin square (6 bits), destination square (6 bits) and flags (4 bits).
+// Squares are the index of their bit, 0 is H1. Unlike Move, it doesn't know the moved or captured pieces,
+// Board.UnpackMove finds them in the board.
+// 0 means no move, since a move can't have the same origin and destination.
+// See https://www.chessprogramming.org/Encoding_Moves
+type PackedMove uint16
+
+// MoveFlags tells the kind of a PackedMove.
+type MoveFlags uint8
+
+const (
+	QuietMove MoveFlags = iota
+	DoublePawnPush
+	KingCastle
+	QueenCastle
+	CaptureMove
+	EnPassantCapture
+)
+
+// Promotions have this bit set, the capture bit and the promotion piece in the 2 lower bits
+const (
+	promotionFlag          MoveFlags = 0b1000
+	captureFlag            MoveFlags = 0b0100
+	promotionPieceMask     MoveFlags = 0b0011
+	KnightPromotion                  = promotionFlag
+	BishopPromotion                  = promotionFlag | 1
+	RookPromotion                    = promotionFlag | 2
+	QueenPromotion                   = promotionFlag | 3
+	KnightPromotionCapture           = KnightPromotion | captureFlag
+	BishopPromotionCapture           = BishopPromotion | captureFlag
+	RookPromotionCapture             = RookPromotion | captureFlag
+	QueenPromotionCapture            = QueenPromotion | captureFlag
+)
+
+func NewPackedMove(from, to int, flags MoveFlags) PackedMove {
+	return PackedMove(from | to<<6 | int(flags)<<12)
+}
+
+func (pm PackedMove) From() int {
+	return int(pm & 0x3F)
+}
+
+func (pm PackedMove) To() int {
+	return int(pm >> 6 & 0x3F)
+}
+
+func (pm PackedMove) Flags() MoveFlags {
+	return MoveFlags(pm >> 12)
+}
+
+func (pm PackedMove) IsCapture() bool {
+	return pm.Flags()&captureFlag != 0
+}
+
+func (pm PackedMove) IsPromotion() bool {
+	return pm.Flags()&promotionFlag != 0
+}
+
+func (pm PackedMove) IsCastling() bool {
+	return pm.Flags() == KingCastle || pm.Flags() == QueenCastle
+}
+
+func (pm PackedMove) IsEnPassant() bool {
+	return pm.Flags() == EnPassantCapture
+}
+
+// PromotionType returns the type of the new piece, or InvalidType if it isn't a promotion.
+func (pm PackedMove) PromotionType() PieceType {
+	if !pm.IsPromotion() {
+		return InvalidType
+	}
+	return KnightType + PieceType(pm.Flags()&promotionPieceMask)
+}
+
+// String returns the move in the same format as Move.StockfishString, e.g. e7e8q.
+func (pm PackedMove) String() string {
+	notation := PositionToString(1<<pm.From()) + PositionToString(1<<pm.To())
+	if pm.IsPromotion() {
+		notation += pm.PromotionType().String()
+	}
+	return notation
+}
+
+func promotionFlags(pieceType PieceType, isCapture bool) MoveFlags {
+	flags := promotionFlag | MoveFlags(pieceType-KnightType)
+	if isCapture {
+		flags |= captureFlag
+	}
+	return flags
+}
+
+// Pack returns the packed representation of the move. The empty move (e.g. a null move) is 0.
+func (m Move) Pack() PackedMove {
+	if m.OldPiecePos == 0 {
+		return 0
+	}
+	var flags MoveFlags
+	switch {
+	case m.IsPromotion:
+		flags = promotionFlags(m.NewPieceType, m.IsCapture)
+	case m.IsEnPassant:
+		flags = EnPassantCapture
+	case m.IsCapture:
+		flags = CaptureMove
+	case m.IsCastling && m.NewPiecePos < m.OldPiecePos:
+		flags = KingCastle
+	case m.IsCastling:
+		flags = QueenCastle
+	case m.Is2SquarePawnMove():
+		flags = DoublePawnPush
+	}
+	return NewPackedMove(bits.TrailingZeros64(m.OldPiecePos), bits.TrailingZeros64(m.NewPiecePos), flags)
+}
+
+// UnpackMove returns the Move of a packed move of the side to move, finding the moved and captured pieces in the board.
+func (b Board) UnpackMove(pm PackedMove) Move {
+	ourPb, enemyPb := b.White, b.Black
+	if !b.Ctx.WhiteTurn {
+		ourPb, enemyPb = b.Black, b.White
+	}
+
+	move := Move{
+		OldPiecePos:  1 << pm.From(),
+		NewPiecePos:  1 << pm.To(),
+		IsCastling:   pm.IsCastling(),
+		IsCapture:    pm.IsCapture(),
+		IsPromotion:  pm.IsPromotion(),
+		IsEnPassant:  pm.IsEnPassant(),
+		NewPieceType: pm.PromotionType(),
+	}
+	move.PieceType = ourPb.GetPieceTypeByPos(move.OldPiecePos)
+	if move.IsEnPassant {
+		move.CapturedPieceType = PawnType
+	} else if move.IsCapture {
+		move.CapturedPieceType = enemyPb.GetPieceTypeByPos(move.NewPiecePos)
+	}
+	return move
+}
+
+// UnpackLegalMove is UnpackMove for a move generated by GenerateLegalMoves, so it can be done with MakeLegalMove.
+func (b Board) UnpackLegalMove(pm PackedMove) Move {
+	move := b.UnpackMove(pm)
+	move.isLegal = true
+	return move
+}
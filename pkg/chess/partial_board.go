@@ -33,64 +33,79 @@ func (pb PartialBoard) AllPossibleMoves(board Board) []Move {
 	return moves
 }
 
+// AllCastlingMoves returns the castling moves allowed by the castling rights, with the rook on its
+// initial square and no pieces between the king and the rook.
+// Whether the king is in check or passes through an attacked square is checked by Board.AllLegalMoves.
 func (pb PartialBoard) AllCastlingMoves(board Board) []Move {
-	anyCastleAvailable := false
+	var canCastleKingSide, canCastleQueenSide bool
 	var kingSideSpaceMask uint64
 	var QueenSideSpaceMask uint64
 	var kingSideSafeSpot uint64
 	var queenSideSafeSpot uint64
+	var kingSideRook, queenSideRook uint64
 	if board.Ctx.WhiteTurn {
-		anyCastleAvailable = board.Ctx.WhiteCastlingKingSide || board.Ctx.WhiteCastlingQueenSide
+		canCastleKingSide = board.Ctx.WhiteCastlingKingSide
+		canCastleQueenSide = board.Ctx.WhiteCastlingQueenSide
 		kingSideSpaceMask = uint64(6)    // 6 is the bits that represents F1 and G1
 		QueenSideSpaceMask = uint64(112) // 112 is the bits that represents B1, C1 and D1
 		kingSideSafeSpot = uint64(2)     // G1
 		queenSideSafeSpot = uint64(32)   // C1
+		kingSideRook = H1
+		queenSideRook = A1
 	} else {
-		anyCastleAvailable = board.Ctx.BlackCastlingKingSide || board.Ctx.BlackCastlingQueenSide
+		canCastleKingSide = board.Ctx.BlackCastlingKingSide
+		canCastleQueenSide = board.Ctx.BlackCastlingQueenSide
 		kingSideSpaceMask = uint64(432_345_564_227_567_616)    // 432_345_564_227_567_616 is the bits that represents F8 and G8
 		QueenSideSpaceMask = uint64(8_070_450_532_247_928_832) // 8_070_450_532_247_928_832 is the bits that represents B8, C8, D8
 		kingSideSafeSpot = uint64(144_115_188_075_855_872)     // G8
 		queenSideSafeSpot = uint64(2_305_843_009_213_693_952)  // C8
+		kingSideRook = H8
+		queenSideRook = A8
 	}
 
-	if !anyCastleAvailable {
+	if !canCastleKingSide && !canCastleQueenSide {
 		return []Move{}
 	}
 
 	moves := make([]Move, 0, 2)
-	allBoardMask := pb.AllBoardMask()
+	// Pieces of both colors block the castling
+	allBoardMask := board.White.AllBoardMask() | board.Black.AllBoardMask()
 	// king side is empty, can castle
-	if kingSideSpaceMask&allBoardMask == 0 {
+	if canCastleKingSide && pb.Rooks.Board&kingSideRook != 0 && kingSideSpaceMask&allBoardMask == 0 {
 		move := Move{OldPiecePos: pb.King.Board, NewPiecePos: kingSideSafeSpot, IsCastling: true, PieceType: KingType}
 		moves = append(moves, move)
 	}
 	// queen side is empty, can castle
-	if QueenSideSpaceMask&allBoardMask == 0 {
+	if canCastleQueenSide && pb.Rooks.Board&queenSideRook != 0 && QueenSideSpaceMask&allBoardMask == 0 {
 		move := Move{OldPiecePos: pb.King.Board, NewPiecePos: queenSideSafeSpot, IsCastling: true, PieceType: KingType}
 		moves = append(moves, move)
 	}
 	return moves
 }
 
-func (pb *PartialBoard) MakeMove(m Move) {
-	var pp *PiecesPosition
-	switch m.PieceType {
+// piecesByType returns the PiecesPosition of the given type.
+func (pb *PartialBoard) piecesByType(pieceType PieceType) *PiecesPosition {
+	switch pieceType {
 	case PawnType:
-		pp = &pb.Pawns
+		return &pb.Pawns
 	case KnightType:
-		pp = &pb.Knights
+		return &pb.Knights
 	case BishopType:
-		pp = &pb.Bishops
+		return &pb.Bishops
 	case RookType:
-		pp = &pb.Rooks
+		return &pb.Rooks
 	case QueenType:
-		pp = &pb.Queens
+		return &pb.Queens
 	case KingType:
-		pp = &pb.King
+		return &pb.King
 	default:
-		log.Fatalf("Invalid piece type: %v", m.PieceType)
+		log.Fatalf("Invalid piece type: %v", pieceType)
+		return nil
 	}
+}
 
+func (pb *PartialBoard) MakeMove(m Move) {
+	pp := pb.piecesByType(m.PieceType)
 	pp.Board &= ^m.OldPiecePos
 	pp.Board |= m.NewPiecePos
 }
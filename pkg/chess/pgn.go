@@ -0,0 +1,39 @@
+package chess
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PGN returns the game in the PGN format.
+// Unknown tags (players, event...) are written as "?".
+func (b Board) PGN() string {
+	result, termination := b.currentResult()
+	moveList := b.GetMoveListInNotation()
+
+	var sb strings.Builder
+	writeTag := func(name, value string) {
+		sb.WriteString(fmt.Sprintf("[%s \"%s\"]\n", name, value))
+	}
+	writeTag("Event", "?")
+	writeTag("Site", "?")
+	writeTag("Date", "????.??.??")
+	writeTag("Round", "?")
+	writeTag("White", "?")
+	writeTag("Black", "?")
+	writeTag("Result", result.String())
+	if startFen := b.startBoard().ToFEN(); startFen != DefaultStartFen {
+		writeTag("SetUp", "1")
+		writeTag("FEN", startFen)
+	}
+	if termination != NoTermination {
+		writeTag("Termination", termination.String())
+	}
+
+	sb.WriteString("\n")
+	if result == NoResult {
+		moveList = strings.TrimSpace(moveList + " *")
+	}
+	sb.WriteString(moveList + "\n")
+	return sb.String()
+}
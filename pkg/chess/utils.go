@@ -17,6 +17,13 @@ func PositionToUInt64(col, row int) uint64 {
 	return 1 << uint(row*8+(7-col))
 }
 
+// PositionToString returns the square name of a single position, e.g. "e4".
+func PositionToString(pos uint64) string {
+	colrow := Int64toPositions(pos)[0]
+	col, row := colrow[0], colrow[1]
+	return fmt.Sprintf("%c%d", "abcdefgh"[col], row+1)
+}
+
 // Int64toPositions converts an int64 to a slice of positions.
 // Positions are represented as [2]int, where the first element is the column and the second element is the row.
 func Int64toPositions(i uint64) [][2]int {
@@ -7,6 +7,10 @@ var (
 	F8 = uint64(288_230_376_151_711_744)
 	A1 = uint64(128)
 	D1 = uint64(16)
+	E1 = uint64(8)
 	A8 = uint64(9_223_372_036_854_775_808)
 	D8 = uint64(1_152_921_504_606_846_976)
+	E8 = uint64(576_460_752_303_423_488)
+
+	lightSquares = uint64(0xAA55AA55AA55AA55) // b1, d1, f1, h1, a2, c2...
 )
@@ -0,0 +1,116 @@
+package chess
+
+import "math/bits"
+
+// Random keys used by the Zobrist hashing.
+// See https://www.chessprogramming.org/Zobrist_Hashing
+var (
+	zobristPieces        [2][7][64]uint64 // [color][PieceType][square], white is 0
+	zobristWhiteTurn     uint64
+	zobristCastling      [4]uint64 // K, Q, k, q
+	zobristEnPassantFile [8]uint64
+)
+
+func init() {
+	// Fixed seed, so the keys are the same in every run
+	seed := uint64(0x9E3779B97F4A7C15)
+	next := func() uint64 { // SplitMix64
+		seed += 0x9E3779B97F4A7C15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+		z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+		return z ^ (z >> 31)
+	}
+
+	for color := range zobristPieces {
+		for pieceType := PawnType; pieceType <= KingType; pieceType++ {
+			for square := range zobristPieces[color][pieceType] {
+				zobristPieces[color][pieceType][square] = next()
+			}
+		}
+	}
+	zobristWhiteTurn = next()
+	for i := range zobristCastling {
+		zobristCastling[i] = next()
+	}
+	for i := range zobristEnPassantFile {
+		zobristEnPassantFile[i] = next()
+	}
+}
+
+// Hash returns the Zobrist hash of the position.
+// It's updated incrementally by MakePseudoLegalMove and restored by UndoMove.
+func (b Board) Hash() uint64 {
+	return b.Ctx.hash
+}
+
+// ComputeHash calculates the Zobrist hash of the position from scratch.
+func (b Board) ComputeHash() uint64 {
+	hash := uint64(0)
+	for _, isWhite := range []bool{true, false} {
+		pb := b.Black
+		if isWhite {
+			pb = b.White
+		}
+		for _, pp := range []PiecesPosition{pb.Pawns, pb.Knights, pb.Bishops, pb.Rooks, pb.Queens, pb.King} {
+			bitboard := pp.Board
+			for bitboard != 0 {
+				hash ^= pieceHash(isWhite, pp.Type, bitboard&-bitboard)
+				bitboard &= bitboard - 1 // Removes the LSB
+			}
+		}
+	}
+
+	if b.Ctx.WhiteTurn {
+		hash ^= zobristWhiteTurn
+	}
+	hash ^= castlingHash(b.Ctx)
+	hash ^= b.enPassantHash()
+	return hash
+}
+
+func pieceHash(isWhite bool, pieceType PieceType, pos uint64) uint64 {
+	color := 1
+	if isWhite {
+		color = 0
+	}
+	return zobristPieces[color][pieceType][bits.TrailingZeros64(pos)]
+}
+
+func castlingHash(ctx Context) uint64 {
+	hash := uint64(0)
+	if ctx.WhiteCastlingKingSide {
+		hash ^= zobristCastling[0]
+	}
+	if ctx.WhiteCastlingQueenSide {
+		hash ^= zobristCastling[1]
+	}
+	if ctx.BlackCastlingKingSide {
+		hash ^= zobristCastling[2]
+	}
+	if ctx.BlackCastlingQueenSide {
+		hash ^= zobristCastling[3]
+	}
+	return hash
+}
+
+// enPassantHash returns the key of the en passant file, only if a pawn of the side to move
+// can capture en passant, otherwise positions that are the same would have different hashes.
+func (b Board) enPassantHash() uint64 {
+	if b.Ctx.EnPassant == 0 {
+		return 0
+	}
+
+	var ourPawns, attackers uint64
+	if b.Ctx.WhiteTurn {
+		ourPawns = b.White.Pawns.Board
+		attackers = moveDown(moveLeft(b.Ctx.EnPassant, 1)|moveRight(b.Ctx.EnPassant, 1), 1)
+	} else {
+		ourPawns = b.Black.Pawns.Board
+		attackers = moveUp(moveLeft(b.Ctx.EnPassant, 1)|moveRight(b.Ctx.EnPassant, 1), 1)
+	}
+	if ourPawns&attackers == 0 {
+		return 0
+	}
+	return zobristEnPassantFile[Int64toPositions(b.Ctx.EnPassant)[0][0]]
+}
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"fmt"
 	"gce/pkg/chess"
 	"strconv"
 	"strings"
@@ -8,8 +9,19 @@ import (
 
 type AnalysisReport struct {
 	BestBoard  chess.Board
-	Evaluation float64
+	Evaluation int // In centipawns, from white's point of view
+	MateIn     int // Moves until mate, positive if white mates and negative if black mates, 0 if there's no mate
 	Moves      []chess.Move
+	Depth      uint
+	Stats      SearchStats
+}
+
+// EvaluationString returns the evaluation in pawns, like "+0.35", or the mate, like "#3" or "#-2".
+func (ar AnalysisReport) EvaluationString() string {
+	if ar.MateIn != 0 {
+		return fmt.Sprintf("#%d", ar.MateIn)
+	}
+	return fmt.Sprintf("%+.2f", float64(ar.Evaluation)/100)
 }
 
 func (ar AnalysisReport) GetEngineLine() string {
@@ -1,55 +1,113 @@
 package engine
 
 import (
-	"fmt"
 	"gce/pkg/chess"
 	"time"
-
-	"github.com/charmbracelet/log"
 )
 
-func rjust(s, fill string, width int) string {
-	for len(s) < width {
-		s = fill + s
-	}
-	return s
+// AnalysisByDepth returns the evaluation of the board by analyzing it to a certain depth.
+func AnalysisByDepth(board *chess.Board, depth uint, config SearchConfig) AnalysisReport {
+	report, _ := analysis(board, depth, config, analysisOptions{})
+	return report
 }
 
-// AnalysisByDepth returns the evaluation of the board by analyzing it to a certain depth.
-func AnalysisByDepth(board *chess.Board, depth uint, returnCh chan AnalysisReport, nodesCountch chan struct{}) AnalysisReport {
-	go minimax(board, depth, returnCh, nodesCountch)
-	nodes := 0
-	startTime := time.Now()
+// analysisOptions are the optional parameters of analysis.
+type analysisOptions struct {
+	counters  *searchCounters  // Shared by the iterations of AnalysisByTime, created if nil
+	orderer   *moveOrderer     // Shared by the iterations of AnalysisByTime, created if nil
+	bestLine  []chess.Move     // Searched first
+	previous  *AnalysisReport  // Previous iteration, its evaluation is used for the aspiration window
+	abortable bool             // If false, the first root move is always searched before aborting
+	stopCh    <-chan struct{}  // Aborts the search when closed
+	maxNodes  uint64           // Aborts the search when the counters reach it, 0 means no limit
+	timeout   <-chan time.Time // Aborts the search when it receives
+}
+
+// analysis analyzes the board to a certain depth.
+// The returned bool is false if the search was aborted, in which case the report only has
+// the best root move found so far (if any) and the statistics.
+func analysis(board *chess.Board, depth uint, config SearchConfig, opts analysisOptions) (AnalysisReport, bool) {
+	abortCh := make(chan struct{})
+	counters := opts.counters
+	if counters == nil {
+		counters = newSearchCounters()
+	}
+	orderer := opts.orderer
+	if orderer == nil {
+		orderer = newMoveOrderer()
+	}
+	tt := config.TT
+	if tt == nil {
+		tt = NewTranspositionTable(0)
+	}
+
+	s := &searcher{
+		tt:        tt,
+		options:   config.Options,
+		counters:  counters,
+		orderer:   orderer,
+		maxNodes:  opts.maxNodes,
+		bestLine:  opts.bestLine,
+		abortCh:   abortCh,
+		abortable: opts.abortable,
+	}
+	if opts.previous != nil {
+		s.hasPreviousScore = true
+		s.previousScore = opts.previous.Evaluation
+		if !board.Ctx.WhiteTurn {
+			s.previousScore = -s.previousScore
+		}
+	}
+	// The helpers copy the board, so they must start before the main searcher modifies it
+	stopHelpers := startHelpers(board, depth, s)
+	returnCh := make(chan AnalysisReport)
+	go s.search(board, depth, returnCh)
+
+	var progressCh <-chan time.Time
+	if config.Progress != nil {
+		interval := config.ProgressInterval
+		if interval == 0 {
+			interval = DefaultProgressInterval
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		progressCh = ticker.C
+	}
+	stopCh, timeout := opts.stopCh, opts.timeout
 	for {
-		diffToStart := time.Now().Sub(startTime)
 		select {
-		case <-nodesCountch:
-			nodes++
-
-			toPrint := fmt.Sprintf("%.2f", float64(nodes)/diffToStart.Seconds())
-			toPrint = rjust(toPrint, " ", 10)
-			fmt.Printf("\rNodes per second: %s", toPrint)
+		case <-progressCh:
+			config.Progress.ReportProgress(counters.stats())
+		case <-stopCh:
+			close(abortCh)
+			stopCh, timeout = nil, nil // Receiving from a nil channel blocks forever
+		case <-timeout:
+			close(abortCh)
+			stopCh, timeout = nil, nil
 		case analysisReport := <-returnCh:
-			fmt.Println()
-			log.Infof("Total time: %s", time.Now().Sub(startTime))
-			return analysisReport
+			stopHelpers()
+			analysisReport.Depth = depth
+			analysisReport.Stats = counters.stats()
+			// The searcher isn't running anymore, so it's safe to read it
+			return analysisReport, !s.aborted
 		}
 	}
 }
 
-// EvaluatePosition returns the evaluation of the current board without doing any moves.
-func EvaluatePosition(board chess.Board) float64 {
+// EvaluatePosition returns the evaluation of the current board without doing any moves,
+// in centipawns and from white's point of view.
+func EvaluatePosition(board chess.Board) int {
 	if board.IsMated() {
 		if board.Ctx.WhiteTurn {
-			return -10000
+			return -MateScore
 		} else {
-			return 10000
+			return MateScore
 		}
 	} else if board.IsDraw() {
 		return 0
 	}
 
-	evaluation := float64(board.MaterialValueBalance())
+	evaluation := int(board.MaterialValueBalance()) * 100
 	evaluation += BoardEvaluationByPieceSquareTable(board)
 	return evaluation
 }
@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"gce/pkg/chess"
+	"sync"
+)
+
+// startHelpers starts the Lazy SMP helper workers, which search copies of the board at the same time as
+// the main searcher, sharing its transposition table and counters. The helpers results are discarded,
+// they only fill the transposition table, so the main searcher finds more cutoffs and better moves to try first.
+// Half of the helpers search one ply deeper, so they don't all follow the same path.
+// The returned function stops the helpers and waits until they finish.
+// See https://www.chessprogramming.org/Lazy_SMP
+func startHelpers(board *chess.Board, depth uint, main *searcher) (stop func()) {
+	helpers := main.options.Threads - 1
+	if helpers <= 0 {
+		return func() {}
+	}
+
+	abortCh := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range helpers {
+		helper := &searcher{
+			tt:        main.tt,
+			options:   main.options,
+			counters:  main.counters,
+			orderer:   newMoveOrderer(),
+			maxNodes:  main.maxNodes,
+			bestLine:  main.bestLine,
+			abortCh:   abortCh,
+			abortable: true,
+		}
+		helperBoard := board.Copy()
+		helperDepth := depth + uint((i+1)%2)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			helper.rootPly = len(helperBoard.MovesDone)
+			helper.negamax(helperBoard, -infinity, infinity, helperDepth, 0)
+		}()
+	}
+
+	return func() {
+		close(abortCh)
+		wg.Wait()
+	}
+}
@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"gce/pkg/chess"
+	"math/bits"
+)
+
+// Move ordering scores, the moves are searched from the highest to the lowest score.
+// See https://www.chessprogramming.org/Move_Ordering
+const (
+	bestLineMoveScore  = 4_000_000
+	hashMoveScore      = 3_000_000
+	noisyMoveScore     = 2_000_000 // Captures and promotions, plus their MoveSortingScore (MVV-LVA)
+	killerMoveScore    = 1_000_000 // The second killer gets one less
+	counterMoveScore   = 900_000
+	maxHistoryScore    = 500_000    // The history is halved when a score reaches it, so quiet moves stay below the others
+	losingCaptureScore = -1_000_000 // Captures with a negative SEE, plus their MoveSortingScore
+)
+
+// Killer moves are stored up to this ply
+const maxKillerPly = 2*MaxDepth + 2
+
+// moveOrderer keeps what the search learns about the quiet moves that cause beta cutoffs,
+// to search them first in other positions. Every searcher has its own, since it isn't safe
+// to use it from several goroutines.
+type moveOrderer struct {
+	// Two quiet moves per ply that caused a cutoff in a sibling node.
+	// See https://www.chessprogramming.org/Killer_Heuristic
+	killers [maxKillerPly][2]chess.PackedMove
+	// Butterfly board indexed by [color][from][to], increased by depth² on each cutoff.
+	// See https://www.chessprogramming.org/History_Heuristic
+	history [2][64][64]int
+	// Quiet move that refuted the previous move, indexed by its [from][to].
+	// See https://www.chessprogramming.org/Countermove_Heuristic
+	counterMoves [64][64]chess.PackedMove
+}
+
+func newMoveOrderer() *moveOrderer {
+	return &moveOrderer{}
+}
+
+func colorIndex(whiteTurn bool) int {
+	if whiteTurn {
+		return 0
+	}
+	return 1
+}
+
+func squares(move chess.Move) (from, to int) {
+	return bits.TrailingZeros64(move.OldPiecePos), bits.TrailingZeros64(move.NewPiecePos)
+}
+
+// counterMove returns the counter move of the last move done, or 0 if there isn't one.
+func (mo *moveOrderer) counterMove(board *chess.Board) chess.PackedMove {
+	if len(board.MovesDone) == 0 || lastMoveIsNull(board) {
+		return 0
+	}
+	from, to := squares(board.MovesDone[len(board.MovesDone)-1])
+	return mo.counterMoves[from][to]
+}
+
+// score returns the ordering score of a quiet move.
+func (mo *moveOrderer) score(board *chess.Board, packed chess.PackedMove, ply uint, counterMove chess.PackedMove) int {
+	if ply < maxKillerPly {
+		if mo.killers[ply][0] == packed {
+			return killerMoveScore
+		}
+		if mo.killers[ply][1] == packed {
+			return killerMoveScore - 1
+		}
+	}
+	if counterMove == packed {
+		return counterMoveScore
+	}
+	return mo.history[colorIndex(board.Ctx.WhiteTurn)][packed.From()][packed.To()]
+}
+
+// update learns from a quiet move that caused a beta cutoff. It must be called before the move is done.
+func (mo *moveOrderer) update(board *chess.Board, move chess.Move, depth, ply uint) {
+	packed := move.Pack()
+	if ply < maxKillerPly && mo.killers[ply][0] != packed {
+		mo.killers[ply][1] = mo.killers[ply][0]
+		mo.killers[ply][0] = packed
+	}
+
+	if len(board.MovesDone) > 0 && !lastMoveIsNull(board) {
+		from, to := squares(board.MovesDone[len(board.MovesDone)-1])
+		mo.counterMoves[from][to] = packed
+	}
+
+	color := colorIndex(board.Ctx.WhiteTurn)
+	from, to := squares(move)
+	mo.history[color][from][to] += int(depth * depth)
+	if mo.history[color][from][to] >= maxHistoryScore {
+		// Aging, so the moves of the current part of the tree count more
+		for from := range mo.history[color] {
+			for to := range mo.history[color][from] {
+				mo.history[color][from][to] /= 2
+			}
+		}
+	}
+}
+
+// movePicker returns the legal moves of a position from the highest to the lowest score. The moves are kept
+// packed, and only the next move is selected and unpacked each time, so when a cutoff happens the rest of
+// the moves are never sorted nor unpacked. It's meant to be declared as a local variable, like chess.MoveList.
+type movePicker struct {
+	board  *chess.Board
+	moves  chess.MoveList
+	scores [chess.MaxMoves]int
+	picked int // Number of moves already returned, they're at the beginning of moves
+}
+
+// reset fills the picker with the legal moves of the board for which keep returns true (all of them if keep is nil).
+func (mp *movePicker) reset(board *chess.Board, keep func(chess.PackedMove) bool, score func(chess.PackedMove) int) {
+	var legalMoves chess.MoveList
+	board.GenerateLegalMoves(&legalMoves)
+	mp.board = board
+	mp.moves.Clear()
+	mp.picked = 0
+	for _, move := range legalMoves.Moves() {
+		if keep == nil || keep(move) {
+			mp.scores[mp.moves.Len()] = score(move)
+			mp.moves.Add(move)
+		}
+	}
+}
+
+// next returns the move with the highest score among the remaining ones, or false if there are no more moves.
+// Moves with the same score are returned in the order they were generated.
+// The board must be in the same position as when the picker was filled.
+func (mp *movePicker) next() (chess.Move, bool) {
+	moves := mp.moves.Moves()
+	if mp.picked == len(moves) {
+		return chess.Move{}, false
+	}
+	best := mp.picked
+	for i := best + 1; i < len(moves); i++ {
+		if mp.scores[i] > mp.scores[best] {
+			best = i
+		}
+	}
+	move, score := moves[best], mp.scores[best]
+	// The moves before the selected one are shifted, so the others keep their order
+	copy(moves[mp.picked+1:best+1], moves[mp.picked:best])
+	copy(mp.scores[mp.picked+1:best+1], mp.scores[mp.picked:best])
+	moves[mp.picked], mp.scores[mp.picked] = move, score
+	mp.picked++
+	return mp.board.UnpackLegalMove(move), true
+}
+
+// noisyMoveSortingScore returns the MoveSortingScore of a packed move, only unpacking captures and promotions,
+// since the score of the quiet moves is always 0.
+func noisyMoveSortingScore(board *chess.Board, move chess.PackedMove) int {
+	if !move.IsCapture() && !move.IsPromotion() {
+		return 0
+	}
+	return MoveSortingScore(board.UnpackMove(move))
+}
+
+// fillMovePicker fills the picker with the legal moves of a negamax node, in this order: the move of the best line
+// of the previous iteration, the transposition table move, captures and promotions by MVV-LVA, killer moves,
+// the counter move, the rest of the quiet moves by their history score and the captures that lose material.
+func (s *searcher) fillMovePicker(picker *movePicker, board *chess.Board, entry TTEntry, found bool, ply uint) {
+	bestLineMove, hasBestLineMove := s.bestLineMove(board, ply)
+	packedBestLineMove := bestLineMove.Pack()
+	counterMove := chess.PackedMove(0)
+	if s.options.OrderingHeuristics {
+		counterMove = s.orderer.counterMove(board)
+	}
+	picker.reset(board, nil, func(move chess.PackedMove) int {
+		switch {
+		case hasBestLineMove && move == packedBestLineMove:
+			return bestLineMoveScore
+		case found && move == entry.bestMove:
+			return hashMoveScore
+		case move.IsCapture() || move.IsPromotion():
+			unpacked := board.UnpackMove(move)
+			if move.IsCapture() && !move.IsPromotion() && s.options.SEE && SEE(board, unpacked) < 0 {
+				return losingCaptureScore + MoveSortingScore(unpacked)
+			}
+			return noisyMoveScore + MoveSortingScore(unpacked)
+		case s.options.OrderingHeuristics:
+			return s.orderer.score(board, move, ply, counterMove)
+		default:
+			return 0
+		}
+	})
+}
@@ -8,29 +8,21 @@ import (
 
 type MoveSlice []chess.Move
 
+// MoveSortingScore returns the static score of a move: promotions first, then captures by MVV-LVA.
+// Quiet moves are 0, the search orders them with the killer, counter move and history heuristics.
 func MoveSortingScore(move chess.Move) int {
 	score := 0
 	if move.IsPromotion {
 		score += 100
 	}
-	if move.IsCheck {
-		score += 2
-	}
 	if move.IsCapture {
-		switch move.CapturedPieceType {
-		case chess.PawnType:
-			score += 1
-		case chess.KnightType:
-			score += 3
-		case chess.BishopType:
-			score += 3
-		case chess.RookType:
-			score += 5
-		case chess.QueenType:
-			score += 9
-		default:
+		// MVV-LVA: the Most Valuable Victim first and, for the same victim, the Least Valuable Attacker
+		victimValue := move.CapturedPieceType.Value()
+		if victimValue == 0 {
 			log.Fatalf("Unknown piece type: %v", move.String())
 		}
+		attackerValue := min(move.PieceType.Value(), uint64(chess.QueenValue)+1) // King value is too big
+		score += int(10*victimValue) + 10 - int(attackerValue)
 	}
 	return score
 }
@@ -0,0 +1,279 @@
+package engine
+
+import (
+	"gce/pkg/chess"
+)
+
+// searcher holds the state shared by all the nodes of a search.
+type searcher struct {
+	tt       *TranspositionTable
+	options  SearchOptions
+	counters *searchCounters
+	orderer  *moveOrderer
+	maxNodes uint64       // The search is aborted when the counters reach it, 0 means no limit
+	rootPly  int          // Number of moves done in the board before the search started
+	bestLine []chess.Move // Best line of the previous iteration, it's searched first
+	// Evaluation of the previous iteration, from the side to move point of view, used for aspiration windows
+	previousScore    int
+	hasPreviousScore bool
+
+	abortCh   <-chan struct{}
+	abortable bool // Set to false to not abort before the first root move is searched
+	aborted   bool // When true, the results of the search are incomplete and must be discarded
+}
+
+// search runs the negamax search from the root and sends its report, with the evaluation from white's point of view.
+func (s *searcher) search(board *chess.Board, depth uint, returnCh chan AnalysisReport) {
+	s.rootPly = len(board.MovesDone)
+	analysisReport := s.aspirationSearch(board, depth)
+	analysisReport.MateIn = mateIn(analysisReport.Evaluation)
+	if !board.Ctx.WhiteTurn {
+		analysisReport.Evaluation = -analysisReport.Evaluation
+		analysisReport.MateIn = -analysisReport.MateIn
+	}
+	returnCh <- analysisReport
+}
+
+// aspirationSearch searches the root with a window around the previous iteration's evaluation,
+// which gives more cutoffs. If the evaluation falls outside the window, the root is searched again
+// with a wider one.
+// See https://www.chessprogramming.org/Aspiration_Windows
+func (s *searcher) aspirationSearch(board *chess.Board, depth uint) AnalysisReport {
+	delta := s.options.AspirationWindow
+	if delta <= 0 || !s.hasPreviousScore || isMateScore(s.previousScore) {
+		return s.negamax(board, -infinity, infinity, depth, 0)
+	}
+
+	alpha, beta := s.previousScore-delta, s.previousScore+delta
+	for {
+		report := s.negamax(board, alpha, beta, depth, 0)
+		if s.aborted {
+			return report
+		}
+
+		if report.Evaluation <= alpha && alpha > -infinity {
+			s.counters.failLows.Add(1)
+		} else if report.Evaluation >= beta && beta < infinity {
+			s.counters.failHighs.Add(1)
+		} else {
+			return report
+		}
+		delta *= 2
+		if delta >= maxAspirationWindow {
+			alpha, beta = -infinity, infinity
+			continue
+		}
+		if report.Evaluation <= alpha {
+			alpha = max(s.previousScore-delta, -infinity)
+		} else {
+			beta = min(s.previousScore+delta, infinity)
+		}
+	}
+}
+
+// isAborted checks if the search must stop.
+func (s *searcher) isAborted() bool {
+	if s.aborted {
+		return true
+	}
+	if !s.abortable {
+		return false
+	}
+	if s.maxNodes > 0 && s.counters.nodes.Load() >= s.maxNodes {
+		s.aborted = true
+		return true
+	}
+	select {
+	case <-s.abortCh:
+		s.aborted = true
+	default:
+	}
+	return s.aborted
+}
+
+// countNode updates the counters when a node is searched.
+func (s *searcher) countNode(ply uint) {
+	s.counters.nodes.Add(1)
+	s.counters.updateSelDepth(ply)
+}
+
+// evaluate returns the evaluation of the position from the side to move point of view,
+// taking into account how far from the root a mate is.
+func (s *searcher) evaluate(board *chess.Board, ply uint) int {
+	if board.IsMated() {
+		return -MateScore + int(ply)
+	}
+	evaluation := EvaluatePosition(*board)
+	if !board.Ctx.WhiteTurn {
+		evaluation = -evaluation
+	}
+	return evaluation
+}
+
+// probeTT looks for the position in the transposition table.
+// If the stored result can be used, it returns the report to return directly, with cutoff set to true.
+// The root (ply 0) is never cut, so the search always returns a move.
+// The entry is also returned, so its best move can be searched first.
+func (s *searcher) probeTT(board *chess.Board, alpha, beta int, depth, ply uint) (report AnalysisReport, entry TTEntry, found, cutoff bool) {
+	s.counters.ttProbes.Add(1)
+	entry, found = s.tt.Probe(board.Hash())
+	if !found {
+		return AnalysisReport{}, entry, false, false
+	}
+	s.counters.ttHits.Add(1)
+	entry.Evaluation = scoreFromTT(entry.Evaluation, ply)
+	if ply > 0 && entry.Depth >= depth && entry.cutoff(alpha, beta) {
+		return AnalysisReport{BestBoard: *board, Evaluation: entry.Evaluation, Moves: []chess.Move{}}, entry, true, true
+	}
+	return AnalysisReport{}, entry, true, false
+}
+
+// bestLineMove returns the move of the previous best line at this ply,
+// only if the moves done since the root are the same of that line.
+func (s *searcher) bestLineMove(board *chess.Board, ply uint) (chess.Move, bool) {
+	if int(ply) >= len(s.bestLine) {
+		return chess.Move{}, false
+	}
+	for i, move := range board.MovesDone[s.rootPly:] {
+		if move != s.bestLine[i] {
+			return chess.Move{}, false
+		}
+	}
+	return s.bestLine[ply], true
+}
+
+// abortedReport returns the result of an aborted node. Only the root returns the best move found
+// so far, which is better than nothing if the search is aborted before completing the first iteration.
+func (s *searcher) abortedReport(bestReport AnalysisReport, bestMove chess.Move, ply uint) AnalysisReport {
+	if ply > 0 || bestMove == (chess.Move{}) {
+		return AnalysisReport{}
+	}
+	bestReport.Moves = append([]chess.Move{bestMove}, bestReport.Moves...)
+	return bestReport
+}
+
+// negamax is an alpha-beta search where the evaluation is always from the side to move point of view,
+// so the score of a move is the negated score of the opponent's reply.
+// See https://www.chessprogramming.org/Negamax
+func (s *searcher) negamax(board *chess.Board, alpha, beta int, depth, ply uint) AnalysisReport {
+	if s.isAborted() {
+		return AnalysisReport{}
+	}
+	if depth == 0 && s.options.Quiescence {
+		return s.quiescence(board, alpha, beta, ply, 0)
+	}
+	s.countNode(ply)
+	// The root is searched if a draw can be claimed, e.g. a repetition that nobody claimed, since the game goes on
+	if board.IsMated() || board.IsAutomaticDraw() || (ply > 0 && board.CanClaimDraw()) || depth == 0 {
+		report := AnalysisReport{BestBoard: *board, Evaluation: s.evaluate(board, ply), Moves: []chess.Move{}}
+		return report
+	}
+
+	ttReport, entry, found, cutoff := s.probeTT(board, alpha, beta, depth, ply)
+	if cutoff {
+		return ttReport
+	}
+
+	// Selectivity: only non PV nodes (searched with a null window) are pruned, so the best line is exact
+	inCheck := board.IsKingInCheck()
+	isPVNode := beta-alpha > 1
+	isFutile, futilityValue := false, 0
+	if ply > 0 && !isPVNode && !inCheck {
+		staticEval := s.evaluate(board, ply)
+		if s.reverseFutilityPruning(staticEval, beta, depth) {
+			return AnalysisReport{BestBoard: *board, Evaluation: staticEval, Moves: []chess.Move{}}
+		}
+		if report, ok := s.nullMovePruning(board, staticEval, beta, depth, ply); ok {
+			return report
+		}
+		futilityValue, isFutile = s.futilityPruning(staticEval, alpha, depth)
+	}
+
+	alphaOrig := alpha
+	var picker movePicker
+	s.fillMovePicker(&picker, board, entry, found, ply)
+	bestReport := AnalysisReport{
+		Evaluation: -infinity,
+	}
+	bestMove := chess.Move{} // Used for saving engine line
+	for i := 0; ; i++ {
+		move, ok := picker.next()
+		if !ok {
+			break
+		}
+		board.MakeLegalMove(move)
+		isQuiet := !move.IsCapture && !move.IsPromotion && !board.IsKingInCheck()
+		if isFutile && isQuiet && i > 0 {
+			board.UndoMove()
+			bestReport.Evaluation = max(bestReport.Evaluation, futilityValue) // Keeps the upper bound valid
+			continue
+		}
+		reduction := uint(0)
+		if !isPVNode {
+			reduction = s.lateMoveReduction(depth, i, isQuiet, inCheck)
+		}
+		report := s.principalVariationSearch(board, alpha, beta, depth, ply, i == 0, reduction)
+		board.UndoMove()
+		if s.aborted {
+			return s.abortedReport(bestReport, bestMove, ply)
+		}
+		if ply == 0 {
+			s.abortable = true // At least one root move was searched
+		}
+
+		report.Evaluation = -report.Evaluation
+		if report.Evaluation > bestReport.Evaluation {
+			bestReport = report
+			bestMove = move
+			alpha = max(alpha, report.Evaluation)
+		}
+		if report.Evaluation >= beta {
+			s.counters.cutoffs.Add(1)
+			if s.options.OrderingHeuristics && !move.IsCapture && !move.IsPromotion {
+				s.orderer.update(board, move, depth, ply)
+			}
+			s.tt.Store(board.Hash(), depth, LowerBound, scoreToTT(report.Evaluation, ply), move)
+			return AnalysisReport{BestBoard: *board, Evaluation: report.Evaluation, Moves: []chess.Move{}}
+		}
+	}
+
+	bound := ExactBound
+	if bestReport.Evaluation <= alphaOrig {
+		bound = UpperBound
+	}
+	s.tt.Store(board.Hash(), depth, bound, scoreToTT(bestReport.Evaluation, ply), bestMove)
+	bestReport.Moves = append([]chess.Move{bestMove}, bestReport.Moves...) // Inserts at the begginning
+	return bestReport
+}
+
+// principalVariationSearch searches the move just made. Only the first move is searched with the full window,
+// the others are searched with a null window, which only proves that they aren't better than alpha.
+// If a move is better, it's searched again with the full window to get its evaluation.
+// Moves with a late move reduction are first searched with the reduced depth and a null window,
+// and only searched with the full depth if they raise alpha.
+// The returned evaluation is from the point of view of the opponent, the side to move after the move.
+// See https://www.chessprogramming.org/Principal_Variation_Search
+func (s *searcher) principalVariationSearch(board *chess.Board, alpha, beta int, depth, ply uint, isFirstMove bool, reduction uint) AnalysisReport {
+	if isFirstMove {
+		return s.negamax(board, -beta, -alpha, depth-1, ply+1)
+	}
+	if reduction > 0 {
+		report := s.negamax(board, -alpha-1, -alpha, depth-1-reduction, ply+1)
+		if s.aborted || -report.Evaluation <= alpha {
+			return report
+		}
+	}
+	if !s.options.PVS {
+		return s.negamax(board, -beta, -alpha, depth-1, ply+1)
+	}
+
+	report := s.negamax(board, -alpha-1, -alpha, depth-1, ply+1)
+	if s.aborted {
+		return report
+	}
+	if evaluation := -report.Evaluation; evaluation > alpha && evaluation < beta {
+		s.counters.researches.Add(1)
+		report = s.negamax(board, -beta, -alpha, depth-1, ply+1)
+	}
+	return report
+}
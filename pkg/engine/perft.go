@@ -1,6 +1,9 @@
 package engine
 
-import "gce/pkg/chess"
+import (
+	"fmt"
+	"gce/pkg/chess"
+)
 
 type NodesPerMove struct {
 	Move  chess.Move
@@ -40,3 +43,61 @@ func Perft(board *chess.Board, depth uint) (uint64, []NodesPerMove) {
 	}
 	return nodes, nodesPerMove
 }
+
+// PerftVerify works like Perft, but after every move is made and undone it checks that the board
+// (bitboards, context and move history) is exactly the same as before the move.
+// The returned error describes the first move whose undo didn't restore the board.
+func PerftVerify(board *chess.Board, depth uint) (uint64, error) {
+	if depth == 0 {
+		return 1, nil
+	}
+
+	nodes := uint64(0)
+	moves := board.AllLegalMoves()
+	for _, move := range moves {
+		before := newBoardState(board)
+		fenBefore := board.ToFEN()
+
+		board.MakeLegalMove(move)
+		_nodes, err := PerftVerify(board, depth-1)
+		// The board is restored even if a deeper move failed, so the caller's board isn't left modified
+		board.UndoMove()
+		if err != nil {
+			return 0, fmt.Errorf("%s %w", move.StockfishString(), err)
+		}
+
+		if newBoardState(board) != before {
+			return 0, fmt.Errorf("%s: undo didn't restore %s, got %s", move.StockfishString(), fenBefore, board.ToFEN())
+		}
+		nodes += _nodes
+	}
+	return nodes, nil
+}
+
+// boardState is what PerftVerify compares before a move and after undoing it: the pieces, the context
+// and the history, of which only the length and the last elements can change.
+type boardState struct {
+	white, black   chess.PartialBoard
+	ctx            chess.Context
+	movesDoneLen   int
+	previousCtxLen int
+	lastMove       chess.Move
+	lastCtx        chess.Context
+}
+
+func newBoardState(board *chess.Board) boardState {
+	state := boardState{
+		white:          board.White,
+		black:          board.Black,
+		ctx:            board.Ctx,
+		movesDoneLen:   len(board.MovesDone),
+		previousCtxLen: len(board.PreviousCtx),
+	}
+	if len(board.MovesDone) > 0 {
+		state.lastMove = board.MovesDone[len(board.MovesDone)-1]
+	}
+	if len(board.PreviousCtx) > 0 {
+		state.lastCtx = board.PreviousCtx[len(board.PreviousCtx)-1]
+	}
+	return state
+}
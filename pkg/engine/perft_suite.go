@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"gce/pkg/chess"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PerftPosition is a position with its expected perft results.
+type PerftPosition struct {
+	Fen           string
+	ExpectedNodes map[uint]uint64 // Depth -> nodes
+}
+
+// ParsePerftEPD reads positions in the EPD format used by perft suites, one per line:
+//
+//	<fen> ;D1 <nodes> ;D2 <nodes> ...
+//
+// The half move clock and move number of the FEN are optional. Empty lines and lines starting with # are ignored.
+func ParsePerftEPD(r io.Reader) ([]PerftPosition, error) {
+	positions := []PerftPosition{}
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, ";")
+		fen := strings.TrimSpace(parts[0])
+		if len(strings.Fields(fen)) == 4 {
+			fen += " 0 1"
+		}
+		if _, err := chess.ParseFEN(fen); err != nil {
+			return nil, errors.New(fmt.Sprintf("Line %d: %v", lineNumber, err))
+		}
+
+		position := PerftPosition{Fen: fen, ExpectedNodes: map[uint]uint64{}}
+		for _, operation := range parts[1:] {
+			fields := strings.Fields(operation)
+			if len(fields) != 2 || !strings.HasPrefix(fields[0], "D") {
+				return nil, errors.New(fmt.Sprintf("Line %d: invalid operation %q", lineNumber, operation))
+			}
+			depth, err := strconv.ParseUint(fields[0][1:], 10, 32)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("Line %d: invalid depth %q", lineNumber, fields[0]))
+			}
+			nodes, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("Line %d: invalid nodes %q", lineNumber, fields[1]))
+			}
+			position.ExpectedNodes[uint(depth)] = nodes
+		}
+		positions = append(positions, position)
+	}
+	return positions, scanner.Err()
+}
+
+// PerftDiff is a move whose nodes count is different from the reference.
+type PerftDiff struct {
+	Move          string
+	Nodes         uint64
+	ExpectedNodes uint64
+	Generated     bool // False if the move is only in the reference
+	InReference   bool // False if the move is only generated by gce
+}
+
+func (d PerftDiff) String() string {
+	if !d.Generated {
+		return fmt.Sprintf("%s: not generated (expected %d)", d.Move, d.ExpectedNodes)
+	}
+	if !d.InReference {
+		return fmt.Sprintf("%s: %d (not in reference)", d.Move, d.Nodes)
+	}
+	return fmt.Sprintf("%s: %d (expected %d)", d.Move, d.Nodes, d.ExpectedNodes)
+}
+
+// ParsePerftDivide reads the nodes per move of a perft divide, in the format printed by
+// Stockfish's "go perft" command (and by gce's perft command):
+//
+//	e2e4: 20
+//
+// Lines in other formats are ignored.
+func ParsePerftDivide(r io.Reader) (map[string]uint64, error) {
+	reference := map[string]uint64{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		move, nodesStr, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found || strings.Contains(move, " ") {
+			continue
+		}
+		nodes, err := strconv.ParseUint(strings.TrimSpace(nodesStr), 10, 64)
+		if err != nil {
+			continue
+		}
+		reference[move] = nodes
+	}
+	return reference, scanner.Err()
+}
+
+// PerftDivideDiff runs Perft and compares the nodes of each move with the reference.
+// Only the moves with a different count are returned, sorted by move.
+// To find a move generator bug, make the first returned move and repeat with depth - 1.
+func PerftDivideDiff(board *chess.Board, depth uint, reference map[string]uint64) []PerftDiff {
+	_, nodesPerMove := Perft(board, depth)
+	diffs := []PerftDiff{}
+	generated := map[string]bool{}
+	for _, nodePerMove := range nodesPerMove {
+		move := nodePerMove.Move.StockfishString()
+		generated[move] = true
+		expected, ok := reference[move]
+		if !ok || expected != nodePerMove.Nodes {
+			diffs = append(diffs, PerftDiff{Move: move, Nodes: nodePerMove.Nodes, ExpectedNodes: expected, Generated: true, InReference: ok})
+		}
+	}
+	for move, expected := range reference {
+		if !generated[move] {
+			diffs = append(diffs, PerftDiff{Move: move, ExpectedNodes: expected, InReference: true})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Move < diffs[j].Move
+	})
+	return diffs
+}
@@ -5,59 +5,59 @@ import (
 	"math/bits"
 )
 
-var whitePawnTable = [64]float64{
-	0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0,
-	0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5,
-	0.1, 0.1, 0.2, 0.3, 0.3, 0.2, 0.1, 0.1,
-	0.05, 0.05, 0.1, 0.25, 0.25, 0.1, 0.05, 0.05,
-	0.0, 0.0, 0.0, 0.2, 0.2, 0.0, 0.0, 0.0,
-	0.05, -0.05, -0.1, 0.0, 0.0, -0.1, -0.05, 0.05,
-	0.05, 0.1, 0.1, -0.2, -0.2, 0.1, 0.1, 0.05,
-	0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0,
+var whitePawnTable = [64]int{
+	0, 0, 0, 0, 0, 0, 0, 0,
+	50, 50, 50, 50, 50, 50, 50, 50,
+	10, 10, 20, 30, 30, 20, 10, 10,
+	5, 5, 10, 25, 25, 10, 5, 5,
+	0, 0, 0, 20, 20, 0, 0, 0,
+	5, -5, -10, 0, 0, -10, -5, 5,
+	5, 10, 10, -20, -20, 10, 10, 5,
+	0, 0, 0, 0, 0, 0, 0, 0,
 }
 
-var whiteKnightTable = [64]float64{
-	-0.5, -0.4, -0.3, -0.3, -0.3, -0.3, -0.4, -0.5,
-	-0.4, -0.2, 0.0, 0.0, 0.0, 0.0, -0.2, -0.4,
-	-0.3, 0.0, 0.1, 0.15, 0.15, 0.1, 0.0, -0.3,
-	-0.3, 0.05, 0.15, 0.2, 0.2, 0.15, 0.05, -0.3,
-	-0.3, 0.0, 0.15, 0.2, 0.2, 0.15, 0.0, -0.3,
-	-0.3, 0.05, 0.1, 0.15, 0.15, 0.1, 0.05, -0.3,
-	-0.4, -0.2, 0.0, 0.05, 0.05, 0.0, -0.2, -0.4,
-	-0.5, -0.4, -0.3, -0.3, -0.3, -0.3, -0.4, -0.5,
+var whiteKnightTable = [64]int{
+	-50, -40, -30, -30, -30, -30, -40, -50,
+	-40, -20, 0, 0, 0, 0, -20, -40,
+	-30, 0, 10, 15, 15, 10, 0, -30,
+	-30, 5, 15, 20, 20, 15, 5, -30,
+	-30, 0, 15, 20, 20, 15, 0, -30,
+	-30, 5, 10, 15, 15, 10, 5, -30,
+	-40, -20, 0, 5, 5, 0, -20, -40,
+	-50, -40, -30, -30, -30, -30, -40, -50,
 }
 
-var whiteBishopTable = [64]float64{
-	-0.2, -0.1, -0.1, -0.1, -0.1, -0.1, -0.1, -0.2,
-	-0.1, 0.05, 0.0, 0.0, 0.0, 0.0, 0.05, -0.1,
-	-0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, -0.1,
-	-0.1, 0.0, 0.1, 0.1, 0.1, 0.1, 0.0, -0.1,
-	-0.1, 0.05, 0.05, 0.1, 0.1, 0.05, 0.05, -0.1,
-	-0.1, 0.0, 0.05, 0.1, 0.1, 0.05, 0.0, -0.1,
-	-0.1, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, -0.1,
-	-0.2, -0.1, -0.1, -0.1, -0.1, -0.1, -0.1, -0.2,
+var whiteBishopTable = [64]int{
+	-20, -10, -10, -10, -10, -10, -10, -20,
+	-10, 5, 0, 0, 0, 0, 5, -10,
+	-10, 10, 10, 10, 10, 10, 10, -10,
+	-10, 0, 10, 10, 10, 10, 0, -10,
+	-10, 5, 5, 10, 10, 5, 5, -10,
+	-10, 0, 5, 10, 10, 5, 0, -10,
+	-10, 0, 0, 0, 0, 0, 0, -10,
+	-20, -10, -10, -10, -10, -10, -10, -20,
 }
 
-var whiteRookTable = [64]float64{
-	0.25, 0.3, 0.3, 0.3, 0.3, 0.3, 0.3, 0.25,
-	0.25, 0.3, 0.3, 0.3, 0.3, 0.3, 0.3, 0.25,
-	0.0, 0.0, 0.0, 0.05, 0.05, 0.0, 0.0, 0.0,
-	-0.05, 0.0, 0.0, 0.05, 0.05, 0.0, 0.0, -0.05,
-	-0.05, 0.0, 0.0, 0.05, 0.05, 0.0, 0.0, -0.05,
-	0.0, 0.0, 0.0, 0.05, 0.05, 0.0, 0.0, 0.0,
-	0.25, 0.3, 0.3, 0.3, 0.3, 0.3, 0.3, 0.25,
-	0.25, 0.3, 0.3, 0.3, 0.3, 0.3, 0.3, 0.25,
+var whiteRookTable = [64]int{
+	25, 30, 30, 30, 30, 30, 30, 25,
+	25, 30, 30, 30, 30, 30, 30, 25,
+	0, 0, 0, 5, 5, 0, 0, 0,
+	-5, 0, 0, 5, 5, 0, 0, -5,
+	-5, 0, 0, 5, 5, 0, 0, -5,
+	0, 0, 0, 5, 5, 0, 0, 0,
+	25, 30, 30, 30, 30, 30, 30, 25,
+	25, 30, 30, 30, 30, 30, 30, 25,
 }
 
-var whiteQueenTable = [64]float64{
-	-0.2, -0.1, -0.1, -0.05, -0.05, -0.1, -0.1, -0.2,
-	-0.1, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, -0.1,
-	-0.1, 0.0, 0.05, 0.05, 0.05, 0.05, 0.0, -0.1,
-	-0.05, 0.0, 0.05, 0.05, 0.05, 0.05, 0.0, -0.05,
-	0.0, 0.0, 0.05, 0.05, 0.05, 0.05, 0.0, 0.0,
-	-0.1, 0.05, 0.05, 0.05, 0.05, 0.05, 0.05, -0.1,
-	-0.1, 0.0, 0.05, 0.0, 0.0, 0.0, 0.0, -0.1,
-	-0.2, -0.1, -0.1, -0.05, -0.05, -0.1, -0.1, -0.2,
+var whiteQueenTable = [64]int{
+	-20, -10, -10, -5, -5, -10, -10, -20,
+	-10, 0, 0, 0, 0, 0, 0, -10,
+	-10, 0, 5, 5, 5, 5, 0, -10,
+	-5, 0, 5, 5, 5, 5, 0, -5,
+	0, 0, 5, 5, 5, 5, 0, 0,
+	-10, 5, 5, 5, 5, 5, 5, -10,
+	-10, 0, 5, 0, 0, 0, 0, -10,
+	-20, -10, -10, -5, -5, -10, -10, -20,
 }
 
 var blackPawnTable = mirrorPST(whitePawnTable)
@@ -66,8 +66,8 @@ var blackBishopTable = mirrorPST(whiteBishopTable)
 var blackRookTable = mirrorPST(whiteRookTable)
 var blackQueenTable = mirrorPST(whiteQueenTable)
 
-func mirrorPST(pst [64]float64) [64]float64 {
-	var mirrored [64]float64
+func mirrorPST(pst [64]int) [64]int {
+	var mirrored [64]int
 	for i := 0; i < 8; i++ {
 		for j := 0; j < 8; j++ {
 			mirrored[i*8+j] = pst[(7-i)*8+j] // Flip along horizontal axis
@@ -76,8 +76,8 @@ func mirrorPST(pst [64]float64) [64]float64 {
 	return mirrored
 }
 
-func pieceTypeTableValue(piecesPosition uint64, pieceType chess.PieceType, isWhite bool) float64 {
-	var table *[64]float64
+func pieceTypeTableValue(piecesPosition uint64, pieceType chess.PieceType, isWhite bool) int {
+	var table *[64]int
 	switch pieceType {
 	case chess.PawnType:
 		if isWhite {
@@ -111,7 +111,7 @@ func pieceTypeTableValue(piecesPosition uint64, pieceType chess.PieceType, isWhi
 		}
 	}
 
-	var value float64
+	var value int
 	for piecesPosition != 0 {
 		i := bits.TrailingZeros64(piecesPosition)
 		value += table[i]
@@ -123,7 +123,8 @@ func pieceTypeTableValue(piecesPosition uint64, pieceType chess.PieceType, isWhi
 	return value
 }
 
-func BoardEvaluationByPieceSquareTable(board chess.Board) float64 {
+// BoardEvaluationByPieceSquareTable returns the positional evaluation of the board, in centipawns.
+func BoardEvaluationByPieceSquareTable(board chess.Board) int {
 	evaluation := pieceTypeTableValue(board.White.Pawns.Board, chess.PawnType, true)
 	evaluation += pieceTypeTableValue(board.White.Knights.Board, chess.KnightType, true)
 	evaluation += pieceTypeTableValue(board.White.Bishops.Board, chess.BishopType, true)
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"gce/pkg/chess"
+)
+
+// Selectivity parameters, depths are in plies and margins in centipawns
+const (
+	reverseFutilityMaxDepth = 3
+	reverseFutilityMargin   = 120 // Per ply of depth
+	nullMoveMinDepth        = 3
+	futilityMaxDepth        = 2
+	futilityMargin          = 200 // Per ply of depth
+	lmrMinDepth             = 3
+	lmrMinMoves             = 3 // Moves searched without reduction, the first ones are the most likely to be the best
+)
+
+// reverseFutilityPruning returns true if the static evaluation is so far above beta that the node
+// would fail high anyway, so it isn't searched. It's only used in non PV nodes close to the leaves.
+// See https://www.chessprogramming.org/Reverse_Futility_Pruning
+func (s *searcher) reverseFutilityPruning(staticEval, beta int, depth uint) bool {
+	return s.options.ReverseFutility && depth <= reverseFutilityMaxDepth && !isMateScore(beta) &&
+		staticEval-reverseFutilityMargin*int(depth) >= beta
+}
+
+// nullMovePruning gives the opponent a free move and searches the position with a reduced depth.
+// If it still fails high, a real move would fail high too, so the node is cut. The report is only valid
+// if the returned bool is true.
+// It isn't done when the side to move only has pawns, where zugzwang positions (every move makes the position
+// worse) are common and passing would be better than any legal move, nor after another null move.
+// See https://www.chessprogramming.org/Null_Move_Pruning
+func (s *searcher) nullMovePruning(board *chess.Board, staticEval, beta int, depth, ply uint) (AnalysisReport, bool) {
+	if !s.options.NullMove || depth < nullMoveMinDepth || staticEval < beta || isMateScore(beta) ||
+		!hasNonPawnMaterial(board) || lastMoveIsNull(board) {
+		return AnalysisReport{}, false
+	}
+
+	reduction := uint(2)
+	if depth >= 7 {
+		reduction = 3
+	}
+	board.MakeNullMove()
+	report := s.negamax(board, -beta, -beta+1, depth-1-min(reduction, depth-1), ply+1)
+	board.UndoMove()
+	if s.aborted {
+		return AnalysisReport{}, true
+	}
+
+	evaluation := -report.Evaluation
+	if evaluation < beta {
+		return AnalysisReport{}, false
+	}
+	if isMateScore(evaluation) {
+		evaluation = beta // The mate isn't proven, since the opponent didn't move
+	}
+	return AnalysisReport{BestBoard: *board, Evaluation: evaluation, Moves: []chess.Move{}}, true
+}
+
+// futilityPruning returns true if the static evaluation is so far below alpha that quiet moves can't raise it,
+// so only captures, promotions and checks are searched. It's only used in non PV nodes close to the leaves.
+// The returned value is the optimistic evaluation of the pruned moves.
+// See https://www.chessprogramming.org/Futility_Pruning
+func (s *searcher) futilityPruning(staticEval, alpha int, depth uint) (int, bool) {
+	futilityValue := staticEval + futilityMargin*int(depth)
+	return futilityValue, s.options.Futility && depth <= futilityMaxDepth && !isMateScore(alpha) && futilityValue <= alpha
+}
+
+// lateMoveReduction returns how many plies less the move is searched. Quiet moves sorted late are
+// rarely the best, so they're searched with a reduced depth and only searched again with the full depth
+// if they raise alpha.
+// See https://www.chessprogramming.org/Late_Move_Reductions
+func (s *searcher) lateMoveReduction(depth uint, moveIndex int, isQuiet, inCheck bool) uint {
+	if !s.options.LMR || inCheck || !isQuiet || depth < lmrMinDepth || moveIndex < lmrMinMoves {
+		return 0
+	}
+	reduction := uint(1)
+	if depth >= 6 && moveIndex >= 2*lmrMinMoves {
+		reduction = 2
+	}
+	return min(reduction, depth-2) // The reduced search is at least 1 ply deep
+}
+
+// hasNonPawnMaterial returns true if the side to move has any piece besides the king and pawns.
+func hasNonPawnMaterial(board *chess.Board) bool {
+	pb := board.Black
+	if board.Ctx.WhiteTurn {
+		pb = board.White
+	}
+	return pb.Knights.Board|pb.Bishops.Board|pb.Rooks.Board|pb.Queens.Board != 0
+}
+
+func lastMoveIsNull(board *chess.Board) bool {
+	return len(board.MovesDone) > 0 && board.MovesDone[len(board.MovesDone)-1].IsNullMove()
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"gce/pkg/chess"
+)
+
+// Used by delta pruning, in centipawns. Captures that can't raise alpha even with this extra
+// positional gain aren't searched.
+const deltaMargin = 200
+
+// quiescence searches the captures and promotions until the position is quiet,
+// so it isn't evaluated in the middle of a capture sequence (horizon effect).
+// See https://www.chessprogramming.org/Quiescence_Search
+func (s *searcher) quiescence(board *chess.Board, alpha, beta int, ply, qply uint) AnalysisReport {
+	if s.isAborted() {
+		return AnalysisReport{}
+	}
+	s.countNode(ply)
+	s.counters.qnodes.Add(1)
+	bestReport := AnalysisReport{BestBoard: *board, Evaluation: s.evaluate(board, ply), Moves: []chess.Move{}}
+	if board.IsMated() || board.IsDraw() {
+		return bestReport
+	}
+
+	// Stand pat: the side to move can choose to not capture, unless it's in check
+	inCheck := board.IsKingInCheck()
+	standPat := bestReport.Evaluation
+	if inCheck {
+		bestReport.Evaluation = -infinity
+	} else {
+		if standPat >= beta {
+			return bestReport
+		}
+		alpha = max(alpha, standPat)
+	}
+
+	bestMove := chess.Move{} // Stays empty if standing pat is the best option
+	var picker movePicker
+	picker.reset(board, func(move chess.PackedMove) bool {
+		return s.isQuiescenceMove(board, move, inCheck, qply)
+	}, func(move chess.PackedMove) int {
+		return noisyMoveSortingScore(board, move)
+	})
+	for move, ok := picker.next(); ok; move, ok = picker.next() {
+		// Delta pruning. The evaluation of the pruned capture is at most its optimistic value, which is
+		// returned if it's the best one, so the result is still a valid upper bound for a fail soft search
+		if optimistic := standPat + captureValue(move) + deltaMargin; !inCheck && move.IsCapture && !move.IsPromotion && optimistic <= alpha {
+			bestReport.Evaluation = max(bestReport.Evaluation, optimistic)
+			continue
+		}
+		// Captures that lose material are rarely better than standing pat
+		if !inCheck && move.IsCapture && !move.IsPromotion && s.options.SEE && SEE(board, move) < 0 {
+			continue
+		}
+
+		board.MakeLegalMove(move)
+		report := s.quiescence(board, -beta, -alpha, ply+1, qply+1)
+		board.UndoMove()
+		if s.aborted {
+			return AnalysisReport{}
+		}
+
+		report.Evaluation = -report.Evaluation
+		if report.Evaluation > bestReport.Evaluation {
+			bestReport = report
+			bestMove = move
+			alpha = max(alpha, report.Evaluation)
+		}
+		if report.Evaluation >= beta {
+			s.counters.cutoffs.Add(1)
+			return AnalysisReport{BestBoard: *board, Evaluation: report.Evaluation, Moves: []chess.Move{}}
+		}
+	}
+
+	if bestMove != (chess.Move{}) {
+		bestReport.Moves = append([]chess.Move{bestMove}, bestReport.Moves...) // Inserts at the begginning
+	}
+	return bestReport
+}
+
+// isQuiescenceMove returns true if the move is searched by the quiescence search:
+// captures, promotions and, if enabled, checks in the first ply.
+// When in check, all the moves are searched, since every evasion must be considered.
+func (s *searcher) isQuiescenceMove(board *chess.Board, move chess.PackedMove, inCheck bool, qply uint) bool {
+	if inCheck || move.IsCapture() || move.IsPromotion() {
+		return true
+	}
+	return s.options.QuiescenceChecks && qply == 0 && givesCheck(board, board.UnpackLegalMove(move))
+}
+
+func givesCheck(board *chess.Board, move chess.Move) bool {
+	board.MakeLegalMove(move)
+	defer board.UndoMove()
+	return board.IsKingInCheck()
+}
+
+// captureValue returns the value of the captured piece, in centipawns.
+func captureValue(move chess.Move) int {
+	return int(move.CapturedPieceType.Value()) * 100
+}
@@ -0,0 +1,50 @@
+package engine
+
+// Evaluations are in centipawns. Mates are scored as MateScore minus the number of plies until the mate,
+// so faster mates are preferred and slower ones are delayed.
+const (
+	MateScore = 100_000
+	// Scores closer to MateScore than this are mates
+	maxMatePly = 1000
+	infinity   = MateScore + 1
+)
+
+// isMateScore returns true if the score is a mate, for either side.
+func isMateScore(score int) bool {
+	return score > MateScore-maxMatePly || score < -MateScore+maxMatePly
+}
+
+// mateIn converts a score to the number of moves until the mate.
+// It's positive when the side of the score mates and negative when it is mated, 0 if it isn't a mate.
+func mateIn(score int) int {
+	if !isMateScore(score) {
+		return 0
+	}
+	if score > 0 {
+		plies := MateScore - score
+		return (plies + 1) / 2
+	}
+	plies := MateScore + score
+	return -plies / 2
+}
+
+// scoreToTT converts a mate score relative to the root to one relative to the node at ply,
+// since the same position can be reached at different plies.
+func scoreToTT(score int, ply uint) int {
+	if score > MateScore-maxMatePly {
+		return score + int(ply)
+	} else if score < -MateScore+maxMatePly {
+		return score - int(ply)
+	}
+	return score
+}
+
+// scoreFromTT does the inverse of scoreToTT.
+func scoreFromTT(score int, ply uint) int {
+	if score > MateScore-maxMatePly {
+		return score - int(ply)
+	} else if score < -MateScore+maxMatePly {
+		return score + int(ply)
+	}
+	return score
+}
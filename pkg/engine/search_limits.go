@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"context"
+	"gce/pkg/chess"
+	"time"
+)
+
+const (
+	MaxDepth = 64
+	// Used to split the remaining clock time when MovesToGo is not set
+	defaultMovesToGo = 30
+)
+
+// SearchLimits tells when AnalysisByTime must stop searching.
+// Zero values mean no limit.
+type SearchLimits struct {
+	Depth     uint
+	Nodes     uint64
+	MoveTime  time.Duration // Exact time to use for the move
+	WhiteTime time.Duration // Remaining clock time
+	BlackTime time.Duration
+	WhiteInc  time.Duration
+	BlackInc  time.Duration
+	MovesToGo uint // Moves until the next time control
+	Infinite  bool // Only stops with the stop signal or at MaxDepth
+}
+
+// TimeBudget returns how much time should be spent in the current move.
+// Returns 0 if there's no time limit.
+func (l SearchLimits) TimeBudget(whiteTurn bool) time.Duration {
+	if l.Infinite {
+		return 0
+	}
+	if l.MoveTime > 0 {
+		return l.MoveTime
+	}
+
+	remaining, inc := l.BlackTime, l.BlackInc
+	if whiteTurn {
+		remaining, inc = l.WhiteTime, l.WhiteInc
+	}
+	if remaining == 0 {
+		return 0
+	}
+
+	movesToGo := l.MovesToGo
+	if movesToGo == 0 {
+		movesToGo = defaultMovesToGo
+	}
+	budget := remaining/time.Duration(movesToGo) + inc/2
+	// Never use more than half of the remaining time
+	if budget > remaining/2 {
+		budget = remaining / 2
+	}
+	return budget
+}
+
+// AnalysisByTime analyzes the board with iterative deepening (depth 1, 2, 3...) until a limit is reached
+// or stopCh is closed, and returns the report of the deepest completed iteration.
+// The statistics of the returned report are the totals of all iterations.
+// onIteration, if not nil, is called with the report of every completed iteration.
+func AnalysisByTime(board *chess.Board, limits SearchLimits, config SearchConfig, stopCh <-chan struct{}, onIteration func(AnalysisReport)) AnalysisReport {
+	report, _ := analysisByTime(board, limits, config, stopCh, onIteration)
+	return report
+}
+
+// analysisByTime is AnalysisByTime, also returning true if stopCh stopped the search before reaching the limits.
+func analysisByTime(board *chess.Board, limits SearchLimits, config SearchConfig, stopCh <-chan struct{}, onIteration func(AnalysisReport)) (AnalysisReport, bool) {
+	startTime := time.Now()
+	depthLimit := limits.Depth
+	if depthLimit == 0 || depthLimit > MaxDepth || limits.Infinite {
+		depthLimit = MaxDepth
+	}
+
+	// The search is aborted at the hard limit, but a new iteration isn't started after the soft limit,
+	// since it usually takes a lot longer than the previous one and its result would be discarded
+	hardLimit := limits.TimeBudget(board.Ctx.WhiteTurn)
+	softLimit := hardLimit / 2
+	if limits.MoveTime > 0 && !limits.Infinite {
+		softLimit = hardLimit
+	}
+	var timeout <-chan time.Time
+	if hardLimit > 0 {
+		timer := time.NewTimer(hardLimit)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	counters := newSearchCounters()
+	orderer := newMoveOrderer() // What an iteration learns helps to order the moves of the next one
+	var best AnalysisReport
+	stopped := false
+	for depth := uint(1); depth <= depthLimit; depth++ {
+		opts := analysisOptions{
+			counters: counters,
+			orderer:  orderer,
+			bestLine: best.Moves,
+			stopCh:   stopCh,
+			timeout:  timeout,
+			maxNodes: limits.Nodes,
+			// The first iteration always searches a root move, so there's always a move to play
+			abortable: depth > 1,
+		}
+		if depth > 1 {
+			opts.previous = &best
+		}
+		report, completed := analysis(board, depth, config, opts)
+		if !completed {
+			// The best move found so far is only used if no iteration was completed
+			if len(best.Moves) == 0 {
+				best = report
+			}
+			stopped = isClosed(stopCh)
+			break
+		}
+		best = report
+		if onIteration != nil {
+			onIteration(best)
+		}
+
+		if len(best.Moves) == 0 {
+			break
+		}
+		if isClosed(stopCh) {
+			stopped = depth < depthLimit
+			break
+		}
+		if limits.Nodes > 0 && best.Stats.Nodes >= limits.Nodes {
+			break
+		}
+		if softLimit > 0 && time.Since(startTime) >= softLimit {
+			break
+		}
+	}
+
+	best.Stats = counters.stats()
+	return best, stopped
+}
+
+// AnalysisWithContext is like AnalysisByTime, but the search is stopped when ctx is canceled or its deadline
+// is exceeded, returning the best result found so far and ctx.Err(). The error is nil if the search reached
+// its limits. The search checks ctx in every node, so it stops promptly, except in the first iteration,
+// where the first root move is always searched, so there's always a move.
+// The board is used during the search, so it must not be used by other goroutines until it returns.
+func AnalysisWithContext(ctx context.Context, board *chess.Board, limits SearchLimits, config SearchConfig, onIteration func(AnalysisReport)) (AnalysisReport, error) {
+	report, stopped := analysisByTime(board, limits, config, ctx.Done(), onIteration)
+	if stopped {
+		return report, ctx.Err()
+	}
+	return report, nil
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
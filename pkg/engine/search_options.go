@@ -0,0 +1,56 @@
+package engine
+
+import "time"
+
+// SearchOptions enables or disables search features, mostly to compare their effect.
+type SearchOptions struct {
+	Quiescence       bool // Searches captures and promotions at the leaves, instead of evaluating them directly
+	QuiescenceChecks bool // Also searches the moves that give check, in the first ply of the quiescence search
+	Threads          int  // Number of goroutines searching at the same time (Lazy SMP)
+	PVS              bool // Searches the moves after the first one with a null window (principal variation search)
+	AspirationWindow int  // Initial half width of the aspiration window in centipawns, 0 disables it
+	// Orders the quiet moves with the killer moves, counter moves and history heuristics, otherwise
+	// they are searched in the order they are generated
+	OrderingHeuristics bool
+	NullMove           bool // Null move pruning
+	LMR                bool // Late move reductions
+	ReverseFutility    bool // Reverse futility pruning (static null move pruning)
+	Futility           bool // Futility pruning of quiet moves near the leaves
+	// Searches the captures that lose material (static exchange evaluation) after the quiet moves,
+	// and prunes them in the quiescence search
+	SEE bool
+}
+
+// Aspiration windows wider than this are replaced by the full window
+const maxAspirationWindow = 1000
+
+var DefaultSearchOptions = SearchOptions{
+	Quiescence:         true,
+	Threads:            1,
+	PVS:                true,
+	AspirationWindow:   50,
+	OrderingHeuristics: true,
+	NullMove:           true,
+	LMR:                true,
+	ReverseFutility:    true,
+	Futility:           true,
+	SEE:                true,
+}
+
+// SearchConfig is everything a search uses besides the board and the limits. It's passed to every search,
+// so concurrent searches can use different options, tables and reporters.
+type SearchConfig struct {
+	Options SearchOptions
+	// Shared by all the searches that use it, so they reuse each other's results. nil disables it
+	TT               *TranspositionTable
+	Progress         ProgressReporter // Receives the statistics of the running search, nil disables it
+	ProgressInterval time.Duration    // How often Progress is called, DefaultProgressInterval if 0
+}
+
+// NewSearchConfig returns a config with the default options and a new transposition table of the default size.
+func NewSearchConfig() SearchConfig {
+	return SearchConfig{
+		Options: DefaultSearchOptions,
+		TT:      NewTranspositionTable(DefaultTTSizeMB),
+	}
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SearchStats are the statistics of a search.
+type SearchStats struct {
+	Nodes    uint64 // Positions searched, including QNodes
+	QNodes   uint64 // Positions searched by the quiescence search
+	TTProbes uint64
+	TTHits   uint64
+	Cutoffs  uint64 // Beta cutoffs
+	SelDepth uint   // Maximum ply reached, including the quiescence search
+	Elapsed  time.Duration
+
+	FailHighs  uint64 // Root evaluations above the aspiration window
+	FailLows   uint64 // Root evaluations below the aspiration window
+	Researches uint64 // Moves searched again with the full window by the principal variation search
+}
+
+// NPS returns the nodes searched per second.
+func (ss SearchStats) NPS() uint64 {
+	return uint64(float64(ss.Nodes) / max(ss.Elapsed.Seconds(), 0.001))
+}
+
+// TTHitRate returns the percentage of transposition table probes that found the position.
+func (ss SearchStats) TTHitRate() float64 {
+	if ss.TTProbes == 0 {
+		return 0
+	}
+	return float64(ss.TTHits) / float64(ss.TTProbes) * 100
+}
+
+// searchCounters are updated atomically while searching, so they can be read by other goroutines.
+type searchCounters struct {
+	startTime time.Time
+	nodes     atomic.Uint64
+	qnodes    atomic.Uint64
+	ttProbes  atomic.Uint64
+	ttHits    atomic.Uint64
+	cutoffs   atomic.Uint64
+	selDepth  atomic.Uint64
+
+	failHighs  atomic.Uint64
+	failLows   atomic.Uint64
+	researches atomic.Uint64
+}
+
+func newSearchCounters() *searchCounters {
+	return &searchCounters{startTime: time.Now()}
+}
+
+func (sc *searchCounters) updateSelDepth(ply uint) {
+	for {
+		selDepth := sc.selDepth.Load()
+		if uint64(ply) <= selDepth || sc.selDepth.CompareAndSwap(selDepth, uint64(ply)) {
+			return
+		}
+	}
+}
+
+func (sc *searchCounters) stats() SearchStats {
+	return SearchStats{
+		Nodes:    sc.nodes.Load(),
+		QNodes:   sc.qnodes.Load(),
+		TTProbes: sc.ttProbes.Load(),
+		TTHits:   sc.ttHits.Load(),
+		Cutoffs:  sc.cutoffs.Load(),
+		SelDepth: uint(sc.selDepth.Load()),
+		Elapsed:  time.Since(sc.startTime),
+
+		FailHighs:  sc.failHighs.Load(),
+		FailLows:   sc.failLows.Load(),
+		Researches: sc.researches.Load(),
+	}
+}
+
+// ProgressReporter receives the statistics of the running search every SearchConfig.ProgressInterval.
+type ProgressReporter interface {
+	ReportProgress(stats SearchStats)
+}
+
+// ProgressFunc is a function used as ProgressReporter.
+type ProgressFunc func(stats SearchStats)
+
+func (f ProgressFunc) ReportProgress(stats SearchStats) {
+	f(stats)
+}
+
+const DefaultProgressInterval = 500 * time.Millisecond
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"gce/pkg/chess"
+	"math/bits"
+)
+
+// SEE returns the material balance of the capture sequence started by the move on its destination square,
+// in centipawns and from the point of view of the side that moves. Each side captures with its least valuable
+// attacker and can stop capturing when it would lose material. Sliders behind the pieces that capture
+// (x-rays) join the sequence, and the king only captures if the square isn't defended.
+// Only the promotion of the move is counted, not the promotions of the recaptures.
+// See https://www.chessprogramming.org/Static_Exchange_Evaluation
+func SEE(board *chess.Board, move chess.Move) int {
+	from, to := squares(move)
+	occupied := board.White.AllBoardMask() | board.Black.AllBoardMask()
+	var gain [32]int // Material balance after each capture, from the point of view of the side that captures
+
+	if move.IsCapture {
+		gain[0] = seeValue(move.CapturedPieceType)
+	}
+	attackerValue := seeValue(move.PieceType)
+	if move.IsPromotion {
+		gain[0] += seeValue(move.NewPieceType) - seeValue(chess.PawnType)
+		attackerValue = seeValue(move.NewPieceType)
+	}
+	occupied &^= 1 << from
+	if move.IsEnPassant {
+		if board.Ctx.WhiteTurn {
+			occupied &^= 1 << (to - 8)
+		} else {
+			occupied &^= 1 << (to + 8)
+		}
+	}
+
+	isWhite := !board.Ctx.WhiteTurn // The side that captures next
+	depth := 0
+	for depth < len(gain)-1 {
+		depth++
+		gain[depth] = attackerValue - gain[depth-1] // If the piece on the square is captured
+		square, pieceType := leastValuableAttacker(board, to, occupied, isWhite)
+		if pieceType == chess.InvalidType {
+			break
+		}
+		occupied &^= 1 << square // Revealing the x-rays behind it
+		attackerValue = seeValue(pieceType)
+		isWhite = !isWhite
+	}
+	// The side that captured last is ignored, nobody captures its piece. Then each side chooses between
+	// capturing and stopping, from the end of the sequence to the beginning
+	for depth--; depth > 0; depth-- {
+		gain[depth-1] = -max(-gain[depth-1], gain[depth])
+	}
+	return gain[0]
+}
+
+// leastValuableAttacker returns the square and type of the least valuable piece of the color that attacks
+// the square, only counting the occupied squares, or InvalidType if there isn't any.
+// The king isn't returned if the square is defended, since capturing would leave it in check.
+func leastValuableAttacker(board *chess.Board, square int, occupied uint64, isWhite bool) (int, chess.PieceType) {
+	pb, enemyPb := board.White, board.Black
+	if !isWhite {
+		pb, enemyPb = board.Black, board.White
+	}
+	attackers := pb.AttackersTo(square, occupied, isWhite) & occupied
+	if attackers == 0 {
+		return 0, chess.InvalidType
+	}
+	for _, piece := range []struct {
+		board     uint64
+		pieceType chess.PieceType
+	}{
+		{pb.Pawns.Board, chess.PawnType},
+		{pb.Knights.Board, chess.KnightType},
+		{pb.Bishops.Board, chess.BishopType},
+		{pb.Rooks.Board, chess.RookType},
+		{pb.Queens.Board, chess.QueenType},
+	} {
+		if attackers&piece.board != 0 {
+			return bits.TrailingZeros64(attackers & piece.board), piece.pieceType
+		}
+	}
+	if enemyPb.AttackersTo(square, occupied&^attackers, !isWhite)&occupied != 0 {
+		return 0, chess.InvalidType
+	}
+	return bits.TrailingZeros64(attackers), chess.KingType
+}
+
+// seeValue returns the value of a piece in centipawns. The king is worth more than all the other pieces together.
+func seeValue(pieceType chess.PieceType) int {
+	if pieceType == chess.KingType {
+		return 10_000
+	}
+	return int(pieceType.Value()) * 100
+}
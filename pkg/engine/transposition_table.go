@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"gce/pkg/chess"
+	"sync/atomic"
+	"unsafe"
+)
+
+const DefaultTTSizeMB = 16
+
+// Bound tells how the evaluation stored in the transposition table relates to the real evaluation.
+type Bound uint8
+
+const (
+	ExactBound Bound = iota
+	LowerBound       // Real evaluation is >= the stored one (fail high)
+	UpperBound       // Real evaluation is <= the stored one (fail low)
+)
+
+// TTEntry is the result of a search stored in the transposition table.
+type TTEntry struct {
+	Depth      uint
+	Bound      Bound
+	Evaluation int // From the side to move point of view, with mates relative to this position
+	bestMove   chess.PackedMove
+}
+
+// IsBestMove returns true if move is the best move of the entry.
+func (entry TTEntry) IsBestMove(move chess.Move) bool {
+	return entry.bestMove != 0 && entry.bestMove == move.Pack()
+}
+
+// Layout of the data of a ttSlot
+const (
+	ttEvaluationShift = 0 // 32 bits
+	ttDepthShift      = 32
+	ttBoundShift      = 40
+	ttMoveShift       = 42
+	ttUsedBit         = 1 << 63 // So the data of a stored entry is never 0
+)
+
+func packTTEntry(entry TTEntry) uint64 {
+	data := uint64(uint32(int32(entry.Evaluation))) << ttEvaluationShift
+	data |= uint64(min(entry.Depth, 0xFF)) << ttDepthShift
+	data |= uint64(entry.Bound&0b11) << ttBoundShift
+	data |= uint64(entry.bestMove) << ttMoveShift
+	return data | ttUsedBit
+}
+
+func unpackTTEntry(data uint64) TTEntry {
+	return TTEntry{
+		Evaluation: int(int32(uint32(data >> ttEvaluationShift))),
+		Depth:      uint(data >> ttDepthShift & 0xFF),
+		Bound:      Bound(data >> ttBoundShift & 0b11),
+		bestMove:   chess.PackedMove(data >> ttMoveShift & 0xFFFF),
+	}
+}
+
+// ttSlot stores an entry in two words, so it can be read and written by several goroutines without locks.
+// The key is the hash XORed with the data, so a slot torn by concurrent writes doesn't match any hash.
+// See https://www.chessprogramming.org/Shared_Hash_Table#Lockless
+type ttSlot struct {
+	key  atomic.Uint64
+	data atomic.Uint64
+}
+
+// TranspositionTable stores the results of previous searches, keyed by the position hash.
+// It's safe to use it from several goroutines.
+// See https://www.chessprogramming.org/Transposition_Table
+type TranspositionTable struct {
+	slots []ttSlot
+}
+
+// NewTranspositionTable creates a table using at most sizeMB megabytes.
+// A size of 0 disables the table.
+func NewTranspositionTable(sizeMB uint) *TranspositionTable {
+	slotSize := uint64(unsafe.Sizeof(ttSlot{}))
+	maxSlots := uint64(sizeMB) * 1024 * 1024 / slotSize
+	// Power of 2, so the index is just a mask of the hash
+	length := uint64(0)
+	if maxSlots > 0 {
+		length = 1
+		for length*2 <= maxSlots {
+			length *= 2
+		}
+	}
+	return &TranspositionTable{slots: make([]ttSlot, length)}
+}
+
+func (tt *TranspositionTable) index(hash uint64) uint64 {
+	return hash & uint64(len(tt.slots)-1)
+}
+
+// Probe returns the entry of the position, if it's stored.
+func (tt *TranspositionTable) Probe(hash uint64) (TTEntry, bool) {
+	if len(tt.slots) == 0 {
+		return TTEntry{}, false
+	}
+
+	slot := &tt.slots[tt.index(hash)]
+	key, data := slot.key.Load(), slot.data.Load()
+	if data == 0 || key^data != hash {
+		return TTEntry{}, false
+	}
+	return unpackTTEntry(data), true
+}
+
+// Store saves the result of a search. An entry of the same position searched with a greater depth is kept.
+func (tt *TranspositionTable) Store(hash uint64, depth uint, bound Bound, evaluation int, bestMove chess.Move) {
+	if len(tt.slots) == 0 {
+		return
+	}
+
+	slot := &tt.slots[tt.index(hash)]
+	key, data := slot.key.Load(), slot.data.Load()
+	if data != 0 && key^data == hash && unpackTTEntry(data).Depth > depth {
+		return
+	}
+	data = packTTEntry(TTEntry{Depth: depth, Bound: bound, Evaluation: evaluation, bestMove: bestMove.Pack()})
+	slot.key.Store(hash ^ data)
+	slot.data.Store(data)
+}
+
+// Clear removes all entries.
+// It must not be called while searching.
+func (tt *TranspositionTable) Clear() {
+	clear(tt.slots)
+}
+
+// Hashfull returns how full the table is, in permille.
+// Only the first 1000 slots are counted, like most engines do.
+func (tt *TranspositionTable) Hashfull() uint64 {
+	sampleSize := min(len(tt.slots), 1000)
+	if sampleSize == 0 {
+		return 0
+	}
+	used := 0
+	for i := range sampleSize {
+		if tt.slots[i].data.Load() != 0 {
+			used++
+		}
+	}
+	return uint64(used * 1000 / sampleSize)
+}
+
+// cutoff returns true if the entry evaluation can be used instead of searching the position.
+func (entry TTEntry) cutoff(alpha, beta int) bool {
+	switch entry.Bound {
+	case ExactBound:
+		return true
+	case LowerBound:
+		return entry.Evaluation >= beta
+	case UpperBound:
+		return entry.Evaluation <= alpha
+	default:
+		return false
+	}
+}
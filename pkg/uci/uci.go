@@ -0,0 +1,345 @@
+package uci
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"gce/pkg/chess"
+	"gce/pkg/engine"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	engineName   = "gce"
+	engineAuthor = "JotaEspig"
+
+	maxHashSizeMB = 1024
+	maxThreads    = 256
+)
+
+// Handler reads UCI commands and writes the engine responses.
+type Handler struct {
+	out   io.Writer
+	outMu sync.Mutex
+	board *chess.Board
+	// Changed by "setoption", the searches use a copy, sharing the transposition table
+	config engine.SearchConfig
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+func NewHandler(out io.Writer) *Handler {
+	return &Handler{
+		out:    out,
+		board:  chess.NewDefaultBoard(),
+		config: engine.NewSearchConfig(),
+	}
+}
+
+// SearchConfig returns the config used by the next search.
+func (h *Handler) SearchConfig() engine.SearchConfig {
+	return h.config
+}
+
+// Run reads commands from in until "quit" is received or the input ends.
+func (h *Handler) Run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if !h.HandleCommand(scanner.Text()) {
+			return nil
+		}
+	}
+	h.stopSearch()
+	return scanner.Err()
+}
+
+// HandleCommand executes a single UCI command.
+// It returns false when the engine should quit.
+func (h *Handler) HandleCommand(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return true
+	}
+
+	switch fields[0] {
+	case "uci":
+		h.send("id name %s", engineName)
+		h.send("id author %s", engineAuthor)
+		h.send("option name Hash type spin default %d min 0 max %d", engine.DefaultTTSizeMB, maxHashSizeMB)
+		h.send("option name Threads type spin default %d min 1 max %d", engine.DefaultSearchOptions.Threads, maxThreads)
+		for _, option := range checkOptions {
+			h.send("option name %s type check default %t", option.name, *option.enabled(&engine.DefaultSearchOptions))
+		}
+		h.send("uciok")
+	case "isready":
+		h.send("readyok")
+	case "ucinewgame":
+		h.stopSearch()
+		h.board = chess.NewDefaultBoard()
+		h.config.TT.Clear()
+	case "setoption":
+		h.stopSearch()
+		if err := setOption(&h.config, fields[1:]); err != nil {
+			h.send("info string %s", err)
+		}
+	case "position":
+		h.stopSearch()
+		board, err := parsePosition(fields[1:])
+		if err != nil {
+			h.send("info string %s", err)
+			break
+		}
+		h.board = board
+	case "go":
+		h.stopSearch()
+		h.startSearch(parseLimits(fields[1:]))
+	case "stop":
+		h.stopSearch()
+	case "quit":
+		h.stopSearch()
+		return false
+	default:
+		h.send("info string Unknown command: %s", fields[0])
+	}
+	return true
+}
+
+func (h *Handler) send(format string, args ...any) {
+	h.outMu.Lock()
+	defer h.outMu.Unlock()
+	fmt.Fprintf(h.out, format+"\n", args...)
+}
+
+// parsePosition parses the arguments of the "position" command:
+// [startpos | fen <fen>] [moves <move1> ... <moveN>]
+func parsePosition(args []string) (*chess.Board, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Missing position arguments")
+	}
+
+	var board *chess.Board
+	movesIdx := len(args)
+	for i, arg := range args {
+		if arg == "moves" {
+			movesIdx = i
+			break
+		}
+	}
+
+	switch args[0] {
+	case "startpos":
+		board = chess.NewDefaultBoard()
+	case "fen":
+		var err error
+		board, err = chess.ParseFEN(strings.Join(args[1:movesIdx], " "))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("Invalid position type: %s", args[0]))
+	}
+
+	if movesIdx == len(args) {
+		return board, nil
+	}
+	for _, moveStr := range args[movesIdx+1:] {
+		move, err := board.ParseStockfishMove(moveStr)
+		if err != nil {
+			return nil, err
+		}
+		board.MakeLegalMove(move)
+	}
+	return board, nil
+}
+
+// setOption parses the arguments of the "setoption" command:
+// name <id> [value <x>]
+func setOption(config *engine.SearchConfig, args []string) error {
+	if len(args) < 2 || args[0] != "name" {
+		return errors.New("Missing option name")
+	}
+
+	valueIdx := len(args)
+	for i, arg := range args {
+		if arg == "value" {
+			valueIdx = i
+			break
+		}
+	}
+	name := strings.Join(args[1:valueIdx], " ")
+	value := ""
+	if valueIdx < len(args) {
+		value = strings.Join(args[valueIdx+1:], " ")
+	}
+
+	switch strings.ToLower(name) {
+	case "hash":
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 || size > maxHashSizeMB {
+			return errors.New(fmt.Sprintf("Invalid Hash value: %s", value))
+		}
+		config.TT = engine.NewTranspositionTable(uint(size))
+	case "threads":
+		threads, err := strconv.Atoi(value)
+		if err != nil || threads < 1 || threads > maxThreads {
+			return errors.New(fmt.Sprintf("Invalid Threads value: %s", value))
+		}
+		config.Options.Threads = threads
+	default:
+		for _, option := range checkOptions {
+			if strings.EqualFold(name, option.name) {
+				enabled, err := strconv.ParseBool(value)
+				if err != nil {
+					return errors.New(fmt.Sprintf("Invalid %s value: %s", option.name, value))
+				}
+				*option.enabled(&config.Options) = enabled
+				return nil
+			}
+		}
+		return errors.New(fmt.Sprintf("Unknown option: %s", name))
+	}
+	return nil
+}
+
+// checkOption is a UCI check option that enables or disables a search feature,
+// so the engine can play matches against itself with and without it.
+type checkOption struct {
+	name    string
+	enabled func(*engine.SearchOptions) *bool
+}
+
+var checkOptions = []checkOption{
+	{"Quiescence", func(o *engine.SearchOptions) *bool { return &o.Quiescence }},
+	{"NullMove", func(o *engine.SearchOptions) *bool { return &o.NullMove }},
+	{"LMR", func(o *engine.SearchOptions) *bool { return &o.LMR }},
+	{"ReverseFutility", func(o *engine.SearchOptions) *bool { return &o.ReverseFutility }},
+	{"Futility", func(o *engine.SearchOptions) *bool { return &o.Futility }},
+	{"SEE", func(o *engine.SearchOptions) *bool { return &o.SEE }},
+}
+
+func parseLimits(args []string) engine.SearchLimits {
+	limits := engine.SearchLimits{}
+	for i := 0; i < len(args); i++ {
+		if args[i] == "infinite" {
+			limits.Infinite = true
+			continue
+		}
+		if i+1 >= len(args) {
+			break
+		}
+
+		value, err := strconv.ParseUint(args[i+1], 10, 64)
+		if err != nil {
+			continue
+		}
+		i++
+		switch args[i-1] {
+		case "depth":
+			limits.Depth = uint(value)
+		case "nodes":
+			limits.Nodes = value
+		case "movetime":
+			limits.MoveTime = time.Duration(value) * time.Millisecond
+		case "wtime":
+			limits.WhiteTime = time.Duration(value) * time.Millisecond
+		case "btime":
+			limits.BlackTime = time.Duration(value) * time.Millisecond
+		case "winc":
+			limits.WhiteInc = time.Duration(value) * time.Millisecond
+		case "binc":
+			limits.BlackInc = time.Duration(value) * time.Millisecond
+		case "inc": // Non standard, same increment for both sides
+			limits.WhiteInc = time.Duration(value) * time.Millisecond
+			limits.BlackInc = time.Duration(value) * time.Millisecond
+		case "movestogo":
+			limits.MovesToGo = uint(value)
+		}
+	}
+
+	noLimits := limits.Depth == 0 && limits.Nodes == 0 && limits.MoveTime == 0 &&
+		limits.WhiteTime == 0 && limits.BlackTime == 0
+	if noLimits {
+		limits.Infinite = true
+	}
+	return limits
+}
+
+func (h *Handler) startSearch(limits engine.SearchLimits) {
+	h.stopCh = make(chan struct{})
+	h.doneCh = make(chan struct{})
+	go h.search(h.board, limits, h.config, h.stopCh, h.doneCh)
+}
+
+// stopSearch stops the current search (if any) and waits for its bestmove to be sent.
+func (h *Handler) stopSearch() {
+	if h.stopCh == nil {
+		return
+	}
+	close(h.stopCh)
+	<-h.doneCh
+	h.stopCh = nil
+	h.doneCh = nil
+}
+
+// search runs AnalysisByTime, sending the info of every completed depth.
+func (h *Handler) search(board *chess.Board, limits engine.SearchLimits, config engine.SearchConfig, stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	if len(board.AllLegalMoves()) == 0 {
+		h.waitIfInfinite(limits, stopCh)
+		h.send("bestmove 0000")
+		return
+	}
+
+	best := engine.AnalysisByTime(board, limits, config, stopCh, func(report engine.AnalysisReport) {
+		h.sendInfo(board, report, config.TT)
+	})
+
+	h.waitIfInfinite(limits, stopCh)
+	// The search only returns no move if the game is over, e.g. a draw by insufficient material
+	if len(best.Moves) == 0 {
+		h.send("bestmove 0000")
+		return
+	}
+	h.send("bestmove %s", best.Moves[0].StockfishString())
+}
+
+// waitIfInfinite blocks until "stop" when searching in infinite mode,
+// as bestmove must not be sent before it.
+func (h *Handler) waitIfInfinite(limits engine.SearchLimits, stopCh chan struct{}) {
+	if limits.Infinite {
+		<-stopCh
+	}
+}
+
+func (h *Handler) sendInfo(board *chess.Board, report engine.AnalysisReport, tt *engine.TranspositionTable) {
+	pv := make([]string, 0, len(report.Moves))
+	for _, move := range report.Moves {
+		pv = append(pv, move.StockfishString())
+	}
+	stats := report.Stats
+	h.send(
+		"info depth %d seldepth %d score %s nodes %d nps %d hashfull %d time %d pv %s",
+		report.Depth, stats.SelDepth, scoreString(board.Ctx.WhiteTurn, report), stats.Nodes, stats.NPS(),
+		tt.Hashfull(), stats.Elapsed.Milliseconds(), strings.Join(pv, " "),
+	)
+}
+
+// scoreString converts the evaluation (from white's point of view) to an UCI score,
+// which is from the side to move point of view.
+func scoreString(whiteTurn bool, report engine.AnalysisReport) string {
+	evaluation, mateIn := report.Evaluation, report.MateIn
+	if !whiteTurn {
+		evaluation, mateIn = -evaluation, -mateIn
+	}
+
+	if mateIn != 0 {
+		return fmt.Sprintf("mate %d", mateIn)
+	}
+	return fmt.Sprintf("cp %d", evaluation)
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"gce/pkg/chess"
+	"math/bits"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// square returns the index of the bit of a square like "e4"
+func square(name string) int {
+	col, row := int(name[0]-'a'), int(name[1]-'1')
+	return bits.TrailingZeros64(chess.PositionToUInt64(col, row))
+}
+
+func squares(names ...string) uint64 {
+	bitboard := uint64(0)
+	for _, name := range names {
+		bitboard |= 1 << square(name)
+	}
+	return bitboard
+}
+
+// rayAttacks walks the rays square by square, like the move generator did before the magic bitboards
+func rayAttacks(sq int, occupied uint64, steps [][2]int) uint64 {
+	attacks := uint64(0)
+	for _, step := range steps {
+		for r, c := sq/8+step[0], sq%8+step[1]; r >= 0 && r < 8 && c >= 0 && c < 8; r, c = r+step[0], c+step[1] {
+			attacks |= 1 << (r*8 + c)
+			if occupied&(1<<(r*8+c)) != 0 {
+				break
+			}
+		}
+	}
+	return attacks
+}
+
+func TestSliderAttacks(t *testing.T) {
+	assert.Equal(t, squares("b1", "c1", "d1", "a2", "a3"), chess.RookAttacks(square("a1"), squares("d1", "a3", "h8")))
+	assert.Equal(t, squares("d5", "c6", "f5", "g6", "h7", "d3", "f3", "g2"), chess.BishopAttacks(square("e4"), squares("c6", "g2", "d3")))
+	assert.Equal(t, 27, bits.OnesCount64(chess.QueenAttacks(square("d4"), 0)))
+
+	rookSteps := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	bishopSteps := [][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+	random := rand.New(rand.NewPCG(1, 2))
+	for range 10_000 {
+		sq := random.IntN(64)
+		occupied := random.Uint64() & random.Uint64()
+		assert.Equal(t, rayAttacks(sq, occupied, rookSteps), chess.RookAttacks(sq, occupied))
+		assert.Equal(t, rayAttacks(sq, occupied, bishopSteps), chess.BishopAttacks(sq, occupied))
+	}
+}
+
+func TestLeaperAttacks(t *testing.T) {
+	assert.Equal(t, squares("b3", "c2"), chess.KnightAttacks(square("a1")))
+	assert.Equal(t, 8, bits.OnesCount64(chess.KnightAttacks(square("e4"))))
+	assert.Equal(t, squares("g8", "g7", "h7"), chess.KingAttacks(square("h8")))
+	assert.Equal(t, squares("d5", "f5"), chess.PawnAttacks(square("e4"), true))
+	assert.Equal(t, squares("d3", "f3"), chess.PawnAttacks(square("e4"), false))
+	assert.Equal(t, squares("b3"), chess.PawnAttacks(square("a2"), true))
+}
+
+func TestAttackersTo(t *testing.T) {
+	board := chess.FenToBoard("4k3/8/2n5/3p1b2/4P3/2N5/4R3/4K3 w - - 0 1")
+	assert.Equal(t, squares("c3", "e2"), board.AttackersTo(square("e4"), true))
+	assert.Equal(t, squares("d5", "f5"), board.AttackersTo(square("e4"), false))
+	assert.Equal(t, squares("c6"), board.AttackersTo(square("d4"), false))
+	assert.True(t, board.IsSquareAttacked(square("d1"), true))
+	assert.False(t, board.IsSquareAttacked(square("e5"), true))
+	assert.True(t, board.IsSquareAttacked(square("e5"), false))
+
+	// Defended pieces count as attacked, sliders are blocked by pieces of any color
+	assert.True(t, board.IsSquareAttacked(square("e1"), true))
+	assert.False(t, board.IsSquareAttacked(square("e6"), true))
+}
+
+func TestAttackMap(t *testing.T) {
+	board := chess.FenToBoard("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	assert.Equal(t, uint64(0xFFFF)<<8|squares("b1", "c1", "d1", "e1", "f1", "g1"), board.AttackMap(true))
+	assert.Equal(t, uint64(0xFFFF)<<40|squares("b8", "c8", "d8", "e8", "f8", "g8"), board.AttackMap(false))
+
+	// The attack map has the same squares as the attackers of each square
+	for _, fen := range []string{
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"4k3/8/2n5/3p1b2/4P3/2N5/4R3/4K3 b - - 0 1",
+	} {
+		board := chess.FenToBoard(fen)
+		for _, isWhite := range []bool{true, false} {
+			attackMap := board.AttackMap(isWhite)
+			for sq := range 64 {
+				assert.Equal(t, attackMap&(1<<sq) != 0, board.IsSquareAttacked(sq, isWhite), "%s %d", fen, sq)
+			}
+		}
+	}
+}
@@ -1,9 +1,67 @@
 package tests
 
 import (
+	"gce/pkg/chess"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
-func TestWhiteKingCastling(t *testing.T) {
+func castlingMoves(b *chess.Board) []string {
+	moves := []string{}
+	for _, move := range b.AllLegalMoves() {
+		if move.IsCastling {
+			moves = append(moves, move.StockfishString())
+		}
+	}
+	return moves
+}
+
+func TestCastlingMoves(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fen      string
+		expected []string
+	}{
+		{"Both sides", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", []string{"e1g1", "e1c1"}},
+		{"Only king side right", "r3k2r/8/8/8/8/8/8/R3K2R w Kkq - 0 1", []string{"e1g1"}},
+		{"Only queen side right", "r3k2r/8/8/8/8/8/8/R3K2R w Qkq - 0 1", []string{"e1c1"}},
+		{"Blocked by enemy piece", "r3k2r/8/8/8/8/8/8/Rn2K1nR w KQkq - 0 1", []string{}},
+		{"Out of check", "r3k2r/8/8/8/8/8/4r3/R3K2R w KQkq - 0 1", []string{}},
+		{"Through check", "r3k2r/8/8/8/8/8/3r1r2/R3K2R w KQkq - 0 1", []string{}},
+		{"Into check", "r3k2r/8/8/8/8/8/2r3r1/R3K2R w KQkq - 0 1", []string{}},
+		{"B1 attacked is allowed", "r3k2r/8/8/8/8/8/1r6/R3K2R w KQkq - 0 1", []string{"e1g1", "e1c1"}},
+		{"Black", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", []string{"e8g8", "e8c8"}},
+		{"Black through check", "r3k2r/3R4/8/8/8/8/8/4K3 b kq - 0 1", []string{"e8g8"}},
+	}
+	for _, tc := range testCases {
+		b := chess.FenToBoard(tc.fen)
+		assert.ElementsMatch(t, tc.expected, castlingMoves(b), tc.name)
+	}
+}
+
+func TestCastlingRightsLost(t *testing.T) {
+	// Rook captured on its initial square
+	b := chess.FenToBoard("r3k2r/6B1/8/8/8/8/6b1/R3K2R w KQkq - 0 1")
+	makeMoves(t, b, "g7h8")
+	assert.Equal(t, "r3k2B/8/8/8/8/8/6b1/R3K2R b KQq - 0 1", b.ToFEN())
+	makeMoves(t, b, "g2h1")
+	assert.Equal(t, "r3k2B/8/8/8/8/8/8/R3K2b w Qq - 0 2", b.ToFEN())
+	b.UndoMove()
+	b.UndoMove()
+	assert.Equal(t, "r3k2r/6B1/8/8/8/8/6b1/R3K2R w KQkq - 0 1", b.ToFEN())
+
+	// Rook moved
+	b = chess.FenToBoard("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	makeMoves(t, b, "h1h2", "a8a7")
+	assert.Equal(t, "4k2r/r7/8/8/8/8/7R/R3K3 w Qk - 2 2", b.ToFEN())
+
+	// King moved
+	makeMoves(t, b, "e1d1")
+	assert.Equal(t, "4k2r/r7/8/8/8/8/7R/R2K4 b k - 3 2", b.ToFEN())
 
+	// Rook that is not on its initial square doesn't change the rights
+	b = chess.FenToBoard("r3k2r/8/8/8/8/8/8/R2RK2R w KQkq - 0 1")
+	makeMoves(t, b, "d1d2")
+	assert.Equal(t, "r3k2r/8/8/8/8/8/3R4/R3K2R b KQkq - 1 1", b.ToFEN())
 }
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"context"
+	"gce/pkg/chess"
+	"gce/pkg/engine"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalysisWithContextTimeout(t *testing.T) {
+	b := chess.FenToBoard(kiwipete)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	startTime := time.Now()
+	report, err := engine.AnalysisWithContext(ctx, b, engine.SearchLimits{}, engine.NewSearchConfig(), nil)
+	assert.Less(t, time.Since(startTime), time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, report.Depth, uint(engine.MaxDepth))
+	assert.NotEmpty(t, report.Moves)
+	assert.Equal(t, kiwipete, b.ToFEN())
+}
+
+func TestAnalysisWithContextCanceled(t *testing.T) {
+	b := chess.FenToBoard(kiwipete)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The first root move is still searched
+	report, err := engine.AnalysisWithContext(ctx, b, engine.SearchLimits{}, engine.NewSearchConfig(), nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotEmpty(t, report.Moves)
+	assert.Equal(t, uint(1), report.Depth)
+	assert.Equal(t, kiwipete, b.ToFEN())
+}
+
+func TestAnalysisWithContextCompletes(t *testing.T) {
+	iterations := 0
+	report, err := engine.AnalysisWithContext(context.Background(), chess.FenToBoard(position3), engine.SearchLimits{Depth: 2}, engine.NewSearchConfig(), func(engine.AnalysisReport) {
+		iterations++
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, iterations)
+	assert.Equal(t, uint(2), report.Depth)
+
+	// Canceling the context after the last iteration doesn't stop the search
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	report, err = engine.AnalysisWithContext(ctx, chess.FenToBoard(position3), engine.SearchLimits{Depth: 2}, engine.NewSearchConfig(), func(report engine.AnalysisReport) {
+		if report.Depth == 2 {
+			cancel()
+		}
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), report.Depth)
+}
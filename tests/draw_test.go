@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"gce/pkg/chess"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeMoves(t *testing.T, b *chess.Board, moves ...string) {
+	for _, moveStr := range moves {
+		move, err := b.ParseStockfishMove(moveStr)
+		if !assert.Nil(t, err, moveStr) {
+			t.FailNow()
+		}
+		b.MakeLegalMove(move)
+	}
+}
+
+func TestThreefoldRepetition(t *testing.T) {
+	b := chess.NewDefaultBoard()
+	assert.Equal(t, 1, b.RepetitionCount())
+
+	knightsShuffle := []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+	makeMoves(t, b, knightsShuffle...)
+	assert.Equal(t, 2, b.RepetitionCount())
+	assert.False(t, b.IsDraw())
+
+	makeMoves(t, b, knightsShuffle[:2]...)
+	assert.Equal(t, 2, b.RepetitionCount())
+	makeMoves(t, b, knightsShuffle[2:]...)
+	assert.Equal(t, 3, b.RepetitionCount())
+	assert.True(t, b.IsDraw())
+	assert.Equal(t, chess.NoResult, b.Ctx.Result)
+	claimed := b.Copy()
+	assert.True(t, claimed.ClaimDraw())
+	assert.Equal(t, chess.Draw, claimed.Ctx.Result)
+	assert.Equal(t, chess.ThreefoldRepetition, claimed.Ctx.Termination)
+
+	makeMoves(t, b, knightsShuffle...)
+	makeMoves(t, b, knightsShuffle...)
+	assert.Equal(t, 5, b.RepetitionCount())
+	assert.True(t, b.IsDraw())
+	assert.Equal(t, chess.NoResult, b.Ctx.Result, "Queries don't change the result")
+	assert.Equal(t, chess.Draw, b.UpdateResult())
+	assert.Equal(t, chess.FivefoldRepetition, b.Ctx.Termination)
+
+	// Position after f3g1 was repeated 4 times
+	b.UndoMove()
+	assert.Equal(t, 4, b.RepetitionCount())
+}
+
+func TestRepetitionNeedsSameRights(t *testing.T) {
+	b := chess.NewDefaultBoard()
+	// White loses the king side castling right after the first rook move,
+	// so the position after h1g1 g1h1 is not the same as the initial one
+	makeMoves(t, b, "g1f3", "g8f6", "h1g1", "f6g8", "g1h1", "g8f6", "f3g1", "f6g8")
+	assert.Equal(t, 1, b.RepetitionCount())
+	makeMoves(t, b, "g1f3", "g8f6", "f3g1", "f6g8")
+	assert.Equal(t, 2, b.RepetitionCount())
+	makeMoves(t, b, "g1f3", "g8f6", "f3g1", "f6g8")
+	assert.Equal(t, 3, b.RepetitionCount())
+	assert.True(t, b.IsDraw())
+
+	// A pawn move makes previous positions unreachable
+	b = chess.NewDefaultBoard()
+	makeMoves(t, b, "g1f3", "g8f6", "f3g1", "f6g8", "e2e4", "e7e5")
+	makeMoves(t, b, "g1f3", "g8f6", "f3g1", "f6g8")
+	assert.Equal(t, 2, b.RepetitionCount())
+	assert.False(t, b.IsDraw())
+}
+
+func TestInsufficientMaterial(t *testing.T) {
+	testCases := []struct {
+		fen          string
+		insufficient bool
+	}{
+		{"8/8/4k3/8/8/3K4/8/8 w - - 0 1", true},     // K vs K
+		{"8/8/4k3/8/8/3KN3/8/8 w - - 0 1", true},    // K+N vs K
+		{"8/8/4k3/8/8/3KB3/8/8 b - - 0 1", true},    // K+B vs K
+		{"8/8/3bk3/8/8/3KB3/8/8 w - - 0 1", true},   // K+B vs K+B, both on dark squares
+		{"8/8/2b1k3/8/8/3KB3/8/8 w - - 0 1", false}, // K+B vs K+B, different colors
+		{"8/8/3nk3/8/8/3KN3/8/8 w - - 0 1", false},  // K+N vs K+N
+		{"8/8/4k3/8/8/3KNN2/8/8 w - - 0 1", false},  // K+N+N vs K
+		{"8/8/4k3/8/8/3KBN2/8/8 w - - 0 1", false},  // K+B+N vs K
+		{"8/8/4k3/8/8/3K4/7P/8 w - - 0 1", false},   // K+P vs K
+		{"8/8/4k3/8/8/3K4/7R/8 w - - 0 1", false},   // K+R vs K
+		{"8/8/4k3/8/8/3K4/7Q/8 w - - 0 1", false},   // K+Q vs K
+	}
+	for _, tc := range testCases {
+		b := chess.FenToBoard(tc.fen)
+		assert.Equal(t, tc.insufficient, b.HasInsufficientMaterial(), tc.fen)
+		assert.Equal(t, tc.insufficient, b.IsAutomaticDraw(), tc.fen)
+		if tc.insufficient {
+			assert.True(t, b.IsDraw(), tc.fen)
+			assert.Equal(t, chess.Draw, b.UpdateResult(), tc.fen)
+			assert.Equal(t, chess.InsufficientMaterial, b.Ctx.Termination, tc.fen)
+		}
+	}
+}
+
+func TestMoveRules(t *testing.T) {
+	b := chess.FenToBoard("8/8/4k3/8/8/3K4/7R/8 w - - 99 80")
+	assert.False(t, b.IsDraw())
+
+	makeMoves(t, b, "h2h1")
+	assert.True(t, b.CanClaimDraw())
+	assert.False(t, b.IsAutomaticDraw())
+	assert.True(t, b.IsDraw())
+	assert.Equal(t, chess.NoResult, b.Ctx.Result)
+	assert.True(t, b.ClaimDraw())
+	assert.Equal(t, chess.Draw, b.Ctx.Result)
+	assert.Equal(t, chess.FiftyMoves, b.Ctx.Termination)
+
+	b = chess.FenToBoard("8/8/4k3/8/8/3K4/7R/8 w - - 149 105")
+	assert.False(t, b.IsAutomaticDraw())
+	makeMoves(t, b, "h2h1")
+	assert.True(t, b.IsAutomaticDraw())
+	assert.True(t, b.IsDraw())
+	assert.Equal(t, chess.Draw, b.UpdateResult())
+	assert.Equal(t, chess.SeventyFiveMoves, b.Ctx.Termination)
+
+	// Checkmate has priority over the move rules
+	b = chess.FenToBoard("4k3/8/4K3/8/8/8/8/7R w - - 149 105")
+	makeMoves(t, b, "h1h8")
+	assert.True(t, b.IsMated())
+	assert.False(t, b.IsDraw())
+}
+
+func TestClaimableRepetition(t *testing.T) {
+	b := chess.NewDefaultBoard()
+	knightsShuffle := []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+	makeMoves(t, b, knightsShuffle...)
+	makeMoves(t, b, knightsShuffle...)
+	assert.True(t, b.CanClaimDraw())
+	assert.False(t, b.IsAutomaticDraw())
+	assert.True(t, b.IsDraw())
+	assert.Equal(t, chess.NoResult, b.Ctx.Result, "Checking the draw doesn't claim it")
+
+	makeMoves(t, b, knightsShuffle...)
+	makeMoves(t, b, knightsShuffle...)
+	assert.True(t, b.IsAutomaticDraw())
+}
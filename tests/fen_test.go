@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"gce/pkg/chess"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToFEN(t *testing.T) {
+	fens := []string{startPosition, schoolMate, foolsMate, kiwipete, position3, position4, position5, position6}
+	for _, fen := range fens {
+		b := chess.FenToBoard(fen)
+		assert.Equal(t, fen, b.ToFEN())
+	}
+}
+
+func TestToFENAfterMoves(t *testing.T) {
+	b := chess.NewDefaultBoard()
+	moves := []string{"e2e4", "c7c5", "g1f3", "d7d6", "f1b5", "c8d7", "e1g1"}
+	expected := []string{
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		"rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2",
+		"rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2",
+		"rnbqkbnr/pp2pppp/3p4/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 0 3",
+		"rnbqkbnr/pp2pppp/3p4/1Bp5/4P3/5N2/PPPP1PPP/RNBQK2R b KQkq - 1 3",
+		"rn1qkbnr/pp1bpppp/3p4/1Bp5/4P3/5N2/PPPP1PPP/RNBQK2R w KQkq - 2 4",
+		"rn1qkbnr/pp1bpppp/3p4/1Bp5/4P3/5N2/PPPP1PPP/RNBQ1RK1 b kq - 3 4",
+	}
+	for i, moveStr := range moves {
+		move, err := b.ParseStockfishMove(moveStr)
+		assert.Nil(t, err)
+		b.MakeLegalMove(move)
+		assert.Equal(t, expected[i], b.ToFEN(), "After %s", moveStr)
+	}
+}
+
+// Every position reachable in 2 plies from the perft positions must round trip
+func TestFENRoundTrip(t *testing.T) {
+	fens := []string{startPosition, kiwipete, position3, position4, position5, position6}
+	for _, fen := range fens {
+		assertRoundTrip(t, chess.FenToBoard(fen), 2)
+	}
+}
+
+func assertRoundTrip(t *testing.T, b *chess.Board, depth int) {
+	fen := b.ToFEN()
+	parsed := chess.FenToBoard(fen)
+	assert.Equal(t, fen, parsed.ToFEN())
+	assert.Equal(t, b.White, parsed.White, fen)
+	assert.Equal(t, b.Black, parsed.Black, fen)
+	assert.Equal(t, b.Ctx.WhiteTurn, parsed.Ctx.WhiteTurn, fen)
+	assert.Equal(t, b.Ctx.EnPassant, parsed.Ctx.EnPassant, fen)
+	if depth == 0 {
+		return
+	}
+
+	// Each move is made on a new board, so the test doesn't depend on UndoMove
+	for _, move := range b.AllLegalMoves() {
+		child := chess.FenToBoard(fen)
+		child.MakeLegalMove(move)
+		assertRoundTrip(t, child, depth-1)
+	}
+}
+
+func TestParseFENErrors(t *testing.T) {
+	testCases := []struct {
+		fen   string
+		field int
+		token string
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -", -1, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -"},
+		{"rnbqkbnr/pppppppp/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", chess.FenPlacementField, "rnbqkbnr/pppppppp/8/8/8/PPPPPPPP/RNBQKBNR"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBN w KQkq - 0 1", chess.FenPlacementField, "RNBQKBN"},
+		{"rnbqkbnr/pppppppp/9/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", chess.FenPlacementField, "9"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPPP/RNBQKBNR w KQkq - 0 1", chess.FenPlacementField, "PPPPPPPPP"},
+		{"rnbqkbnr/pppppppp/44/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", chess.FenPlacementField, "44"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQXBNR w KQkq - 0 1", chess.FenPlacementField, "RNBQXBNR"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQQBNR w kq - 0 1", chess.FenPlacementField, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQQBNR"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPKPPP/RNBQKBNR w kq - 0 1", chess.FenPlacementField, "rnbqkbnr/pppppppp/8/8/8/8/PPPPKPPP/RNBQKBNR"},
+		{"rnbqkbnp/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQq - 0 1", chess.FenPlacementField, "rnbqkbnp"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1", chess.FenTurnField, "x"},
+		{"4k3/8/8/8/8/8/8/4RK2 w - - 0 1", chess.FenTurnField, "w"},
+		{"4k3/8/8/8/8/8/5n2/7K b - - 0 1", chess.FenTurnField, "b"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkx - 0 1", chess.FenCastlingField, "KQkx"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KKkq - 0 1", chess.FenCastlingField, "KKkq"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBN1 w KQkq - 0 1", chess.FenCastlingField, "KQkq"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQ1BNR w KQkq - 0 1", chess.FenPlacementField, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQ1BNR"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBKQBNR w KQkq - 0 1", chess.FenCastlingField, "KQkq"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e9 0 1", chess.FenEnPassantField, "e9"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e3 0 1", chess.FenEnPassantField, "e3"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq e3 0 1", chess.FenEnPassantField, "e3"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - x 1", chess.FenHalfMovesField, "x"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - -1 1", chess.FenHalfMovesField, "-1"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 y", chess.FenMoveNumberField, "y"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 0", chess.FenMoveNumberField, "0"},
+	}
+
+	for _, tc := range testCases {
+		b, err := chess.ParseFEN(tc.fen)
+		assert.Nil(t, b, tc.fen)
+		if !assert.Error(t, err, tc.fen) {
+			continue
+		}
+		fenErr, ok := err.(*chess.FenError)
+		if !assert.True(t, ok, tc.fen) {
+			continue
+		}
+		assert.Equal(t, tc.field, fenErr.Field, "%s: %v", tc.fen, err)
+		assert.Equal(t, tc.token, fenErr.Token, "%s: %v", tc.fen, err)
+		assert.NotEmpty(t, fenErr.Reason, tc.fen)
+	}
+}
+
+func TestFenErrorMessage(t *testing.T) {
+	_, err := chess.ParseFEN("8/8/8/8/8/8/8/K6k w")
+	assert.EqualError(t, err, `Invalid FEN "8/8/8/8/8/8/8/K6k w": expected 6 fields, got 2`)
+	_, err = chess.ParseFEN("8/8/8/8/8/8/8/K6k x - - 0 1")
+	assert.EqualError(t, err, `Invalid FEN side to move (field 1) "x": expected w or b`)
+}
+
+func TestFenToBoardPlacementOnly(t *testing.T) {
+	b := chess.FenToBoard("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR")
+	assert.Equal(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1", b.ToFEN())
+}
+
+func TestParseFENValid(t *testing.T) {
+	fens := []string{
+		startPosition, kiwipete, position3, position4, position5, position6,
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		"rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2",
+		// Extra whitespace is ignored
+		"  8/8/8/8/8/8/8/K6k   w - -  0 1 ",
+	}
+	for _, fen := range fens {
+		b, err := chess.ParseFEN(fen)
+		assert.Nil(t, err, fen)
+		assert.NotNil(t, b, fen)
+	}
+}
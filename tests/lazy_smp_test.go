@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"fmt"
+	"gce/pkg/chess"
+	"gce/pkg/engine"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazySMP(t *testing.T) {
+	// Without transposition table the helpers can't change what the main searcher finds
+	config := engine.SearchConfig{Options: engine.DefaultSearchOptions}
+	single := engine.AnalysisByDepth(chess.FenToBoard(position4), 4, config)
+	config.Options.Threads = 4
+	b := chess.FenToBoard(position4)
+	parallel := engine.AnalysisByDepth(b, 4, config)
+	assert.Equal(t, position4, b.ToFEN())
+	assert.Equal(t, uint(4), parallel.Depth)
+	assert.Equal(t, single.Evaluation, parallel.Evaluation)
+	assert.Equal(t, single.Moves, parallel.Moves)
+	assert.Contains(t, b.AllLegalMoves(), parallel.Moves[0])
+
+	// With a shared table the result can change, but it must still be a legal move with a real score
+	config = engine.NewSearchConfig()
+	config.Options.Threads = 4
+	shared := engine.AnalysisByDepth(b, 4, config)
+	assert.Equal(t, uint(4), shared.Depth)
+	assert.Contains(t, b.AllLegalMoves(), shared.Moves[0])
+	assert.Less(t, shared.Evaluation, engine.MateScore-engine.MaxDepth)
+	assert.Greater(t, shared.Evaluation, -engine.MateScore+engine.MaxDepth)
+}
+
+func TestLazySMPStop(t *testing.T) {
+	config := engine.NewSearchConfig()
+	config.Options.Threads = 4
+	b := chess.FenToBoard(position4)
+
+	startTime := time.Now()
+	report := engine.AnalysisByTime(b, engine.SearchLimits{MoveTime: 200 * time.Millisecond}, config, nil, nil)
+	assert.Less(t, time.Since(startTime), 2*time.Second)
+	assert.NotEmpty(t, report.Moves)
+	assert.Equal(t, position4, b.ToFEN())
+}
+
+// BenchmarkLazySMP measures the time to reach depth 6 (ns/op) with different numbers of threads.
+// The helpers only make the search faster if there are enough CPU cores to run them at the same time.
+func BenchmarkLazySMP(b *testing.B) {
+	for _, threads := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("threads=%d", threads), func(b *testing.B) {
+			config := engine.NewSearchConfig()
+			config.Options.Threads = threads
+			nodes := uint64(0)
+			for range b.N {
+				config.TT.Clear()
+				report := engine.AnalysisByTime(chess.FenToBoard(kiwipete), engine.SearchLimits{Depth: 6}, config, nil, nil)
+				nodes += report.Stats.Nodes
+			}
+			b.ReportMetric(float64(nodes)/float64(b.N), "nodes/op")
+		})
+	}
+}
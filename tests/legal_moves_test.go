@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"gce/pkg/chess"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// referenceLegalMoves filters the pseudo legal moves by making them and checking if the king is left
+// in check, like the move generator did before computing the checks and pins.
+func referenceLegalMoves(b *chess.Board) []string {
+	legalMoves := []string{}
+	for _, move := range b.GenerateAllMoves() {
+		b.MakePseudoLegalMove(move)
+		isValid := b.IsValidPosition()
+		b.UndoMove()
+
+		if isValid && move.IsCastling {
+			// Can't castle out of check nor through an attacked square
+			isValid = !b.IsKingInCheck()
+			midPoint := move.OldPiecePos >> 1 // King side
+			if move.NewPiecePos > move.OldPiecePos {
+				midPoint = move.OldPiecePos << 1 // Queen side
+			}
+			b.MakePseudoLegalMove(chess.Move{OldPiecePos: move.OldPiecePos, NewPiecePos: midPoint, PieceType: chess.KingType})
+			isValid = isValid && b.IsValidPosition()
+			b.UndoMove()
+		}
+		if isValid {
+			legalMoves = append(legalMoves, move.StockfishString())
+		}
+	}
+	sort.Strings(legalMoves)
+	return legalMoves
+}
+
+func legalMoves(b *chess.Board) []string {
+	legalMoves := []string{}
+	for _, move := range b.AllLegalMoves() {
+		legalMoves = append(legalMoves, move.StockfishString())
+	}
+	sort.Strings(legalMoves)
+	return legalMoves
+}
+
+// assertLegalMovesThroughTree compares the legal move generator with the reference in every position
+// of the tree, and returns the number of leaves (perft).
+func assertLegalMovesThroughTree(t *testing.T, b *chess.Board, depth int) int {
+	if !assert.Equal(t, referenceLegalMoves(b), legalMoves(b), b.ToFEN()) {
+		t.FailNow()
+	}
+	if depth == 0 {
+		return 1
+	}
+
+	nodes := 0
+	for _, move := range b.AllLegalMoves() {
+		b.MakeLegalMove(move)
+		nodes += assertLegalMovesThroughTree(t, b, depth-1)
+		b.UndoMove()
+	}
+	return nodes
+}
+
+func TestLegalMoveGenerator(t *testing.T) {
+	testCases := []struct {
+		name  string
+		fen   string
+		depth int
+	}{
+		{"start position", startPosition, 3},
+		{"kiwipete", kiwipete, 2},
+		{"position 3", position3, 3},
+		{"position 4", position4, 2},
+		{"position 5", position5, 2},
+		{"position 6", position6, 2},
+		{"double check", "4k3/8/8/8/1b6/8/2N5/r3K2R w K - 0 1", 3},
+		{"en passant discovers a check on the rank", "8/8/8/8/k2Pp2Q/8/8/3K4 b - d3 0 1", 3},
+		{"en passant discovers a check on the diagonal", "8/8/8/8/4pP2/8/8/1K4kB b - f3 0 1", 3},
+		{"en passant captures the checking pawn", "8/8/8/2k5/3Pp3/8/8/4K3 b - d3 0 1", 3},
+		{"castling through attacked squares", "r3k2r/8/8/8/8/8/6p1/R3K2R w KQkq - 0 1", 2},
+		{"castling with an attacked rook", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", 2},
+		{"pinned pieces", "4k3/4r3/8/b7/8/2N5/3B4/r1NRK3 w - - 0 1", 3},
+		{"pinned knight in check", "4k3/8/8/8/1b6/8/3N4/r3K2R w K - 0 1", 3},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assertLegalMovesThroughTree(t, chess.FenToBoard(tc.fen), tc.depth)
+		})
+	}
+}
+
+func TestLegalMovesSpecialCases(t *testing.T) {
+	// Only the king can move in double check
+	assert.Equal(t, []string{"e1e2", "e1f2"}, legalMoves(chess.FenToBoard("4k3/8/8/8/1b6/8/2N5/r3K2R w K - 0 1")))
+	// The en passant capture would leave the king in check by the queen
+	assert.NotContains(t, legalMoves(chess.FenToBoard("8/8/8/8/k2Pp2Q/8/8/3K4 b - d3 0 1")), "e4d3")
+	// The en passant capture removes the pawn giving check
+	assert.Contains(t, legalMoves(chess.FenToBoard("8/8/8/2k5/3Pp3/8/8/4K3 b - d3 0 1")), "e4d3")
+	// The king can't castle through f1, attacked by the pawn
+	board := chess.FenToBoard("r3k2r/8/8/8/8/8/6p1/R3K2R w KQkq - 0 1")
+	assert.NotContains(t, legalMoves(board), "e1g1")
+	assert.Contains(t, legalMoves(board), "e1c1")
+}
+
+func BenchmarkLegalMoves(b *testing.B) {
+	board := chess.FenToBoard(kiwipete)
+	var list chess.MoveList
+	for i := 0; i < b.N; i++ {
+		list.Clear()
+		board.GenerateLegalMoves(&list)
+	}
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"gce/pkg/chess"
+	"gce/pkg/engine"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,5 +17,61 @@ func TestMakeMove(t *testing.T) {
 }
 
 func TestUndoMove(t *testing.T) {
+	testCases := []struct {
+		fen  string
+		move string
+	}{
+		{startPosition, "e2e4"},
+		{startPosition, "g1f3"},
+		{kiwipete, "e1g1"},                            // Castling
+		{kiwipete, "e1c1"},                            // Castling
+		{kiwipete, "e5f7"},                            // Capture
+		{position3, "e2e4"},                           // Double pawn move
+		{"r3k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7a8q"}, // Capture promotion
+		{"r3k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7a8n"}, // Capture promotion
+		{position5, "d7c8q"},                          // Capture promotion
+		{position5, "d7c8r"},                          // Capture promotion
+		{"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8q"},   // Promotion
+		{"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8b"},   // Promotion
+		{"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", "e5f6"}, // En passant
+		{"rnbqkbnr/pppp1ppp/8/8/3Pp3/8/PPP1PPPP/RNBQKBNR b KQkq d3 0 2", "e4d3"},  // En passant
+		{"4k3/8/8/8/Pp6/8/8/4K3 b - a3 0 1", "b4a3"},                              // En passant on the edge
+	}
+	for _, tc := range testCases {
+		b := chess.FenToBoard(tc.fen)
+		before := *b
+		move, err := b.ParseStockfishMove(tc.move)
+		if !assert.Nil(t, err, "%s %s", tc.fen, tc.move) {
+			continue
+		}
+		b.MakeLegalMove(move)
+		assert.NotEqual(t, tc.fen, b.ToFEN())
+		b.UndoMove()
+		assert.Equal(t, tc.fen, b.ToFEN(), tc.move)
+		assert.Equal(t, before.White, b.White, tc.move)
+		assert.Equal(t, before.Black, b.Black, tc.move)
+		assert.Equal(t, before.Ctx, b.Ctx, tc.move)
+		assert.Empty(t, b.MovesDone)
+	}
+}
 
+func TestPerftMakeUndoVerification(t *testing.T) {
+	testCases := []struct {
+		fen   string
+		depth uint
+	}{
+		{startPosition, 3},
+		{kiwipete, 2},
+		{position3, 3},
+		{position4, 3},
+		{position5, 2},
+		{position6, 2},
+	}
+	for _, tc := range testCases {
+		b := chess.FenToBoard(tc.fen)
+		_, err := engine.PerftVerify(b, tc.depth)
+		assert.Nil(t, err, tc.fen)
+		assert.Equal(t, tc.fen, b.ToFEN())
+		assert.Empty(t, b.MovesDone)
+	}
 }
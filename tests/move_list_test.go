@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"gce/pkg/chess"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackedMove(t *testing.T) {
+	move := chess.NewPackedMove(square("e7"), square("d8"), chess.QueenPromotionCapture)
+	assert.Equal(t, square("e7"), move.From())
+	assert.Equal(t, square("d8"), move.To())
+	assert.True(t, move.IsCapture())
+	assert.True(t, move.IsPromotion())
+	assert.False(t, move.IsCastling())
+	assert.Equal(t, chess.QueenType, move.PromotionType())
+	assert.Equal(t, "e7d8q", move.String())
+
+	castle := chess.NewPackedMove(square("e1"), square("g1"), chess.KingCastle)
+	assert.True(t, castle.IsCastling())
+	assert.False(t, castle.IsCapture())
+	assert.Equal(t, chess.InvalidType, castle.PromotionType())
+	assert.Equal(t, chess.PackedMove(0), chess.Move{}.Pack())
+}
+
+func TestMoveList(t *testing.T) {
+	var list chess.MoveList
+	chess.NewDefaultBoard().GeneratePseudoLegalMoves(&list)
+	assert.Equal(t, 20, list.Len())
+	assert.Equal(t, "h2h3", list.At(0).String())
+	assert.Len(t, list.Moves(), 20)
+
+	list.Clear()
+	assert.Equal(t, 0, list.Len())
+	// The position with the most legal moves known
+	chess.FenToBoard("R6R/3Q4/1Q4Q1/4Q3/2Q4Q/Q4Q2/pp1Q4/kBNN1KB1 w - - 0 1").GeneratePseudoLegalMoves(&list)
+	assert.Equal(t, 218, list.Len())
+}
+
+// TestPackedMoveGenerator compares the packed move generator with the Move generators of each piece,
+// and checks that packing and unpacking the moves gives the same moves.
+func TestPackedMoveGenerator(t *testing.T) {
+	for _, fen := range []string{startPosition, kiwipete, position3, position4, position5, position6} {
+		assertPackedMovesThroughTree(t, chess.FenToBoard(fen), 2)
+	}
+}
+
+func assertPackedMovesThroughTree(t *testing.T, b *chess.Board, depth int) {
+	pb := b.Black
+	if b.Ctx.WhiteTurn {
+		pb = b.White
+	}
+	expected := append(pb.AllPossibleMoves(*b), pb.AllCastlingMoves(*b)...)
+	if !assert.Equal(t, expected, b.GenerateAllMoves(), b.ToFEN()) {
+		t.FailNow()
+	}
+
+	// The search compares the generated packed moves with the packed Moves, e.g. the killer moves
+	var legalMoves chess.MoveList
+	b.GenerateLegalMoves(&legalMoves)
+	for i, move := range b.AllLegalMoves() {
+		assert.Equal(t, legalMoves.At(i), move.Pack(), move.StockfishString())
+		unpacked := b.UnpackMove(move.Pack())
+		assert.Equal(t, move.StockfishString(), unpacked.StockfishString())
+		assert.Equal(t, move.PieceType, unpacked.PieceType)
+		assert.Equal(t, move.CapturedPieceType, unpacked.CapturedPieceType)
+		assert.Equal(t, move.IsCastling, unpacked.IsCastling)
+		assert.Equal(t, move.IsEnPassant, unpacked.IsEnPassant)
+		if depth > 1 {
+			b.MakeLegalMove(move)
+			assertPackedMovesThroughTree(t, b, depth-1)
+			b.UndoMove()
+		}
+	}
+}
+
+func BenchmarkGenerateMoveList(b *testing.B) {
+	board := chess.FenToBoard(kiwipete)
+	var list chess.MoveList
+	for i := 0; i < b.N; i++ {
+		list.Clear()
+		board.GeneratePseudoLegalMoves(&list)
+	}
+}
+
+func BenchmarkGenerateMoveSlice(b *testing.B) {
+	board := chess.FenToBoard(kiwipete)
+	for i := 0; i < b.N; i++ {
+		board.GenerateAllMoves()
+	}
+}
@@ -3,24 +3,119 @@ package tests
 import (
 	"gce/pkg/chess"
 	"gce/pkg/engine"
+	"os"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-// For now (09/02/2025), it generates more moves than the expected for depth > 2
-func TestPerftInitialBoard(t *testing.T) {
-	expectedNodes := []uint64{20, 400, 8902, 197_281, 4_865_609, 119_060_324}
-	for depth := 1; depth <= 5; depth++ {
-		initialBoard := chess.NewDefaultBoard()
-		nodes, _ := engine.Perft(initialBoard, uint(depth))
-		assert.Equal(t, expectedNodes[depth-1], nodes, "Depth %d", depth)
+// Depths with more expected nodes than this are skipped, they take too long.
+// The full run includes the start position at depth 5 and Kiwipete at depth 4
+const (
+	perftMaxNodes      = 5_000_000
+	perftMaxNodesShort = 100_000
+)
+
+func TestPerftSuite(t *testing.T) {
+	file, err := os.Open("testdata/perft.epd")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer file.Close()
+	positions, err := engine.ParsePerftEPD(file)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	maxNodes := uint64(perftMaxNodes)
+	if testing.Short() {
+		maxNodes = perftMaxNodesShort
+	}
+	for _, position := range positions {
+		depths := make([]uint, 0, len(position.ExpectedNodes))
+		for depth := range position.ExpectedNodes {
+			depths = append(depths, depth)
+		}
+		sort.Slice(depths, func(i, j int) bool { return depths[i] < depths[j] })
+
+		for _, depth := range depths {
+			expected := position.ExpectedNodes[depth]
+			if expected > maxNodes {
+				continue
+			}
+			b := chess.FenToBoard(position.Fen)
+			nodes, _ := engine.Perft(b, depth)
+			assert.Equal(t, expected, nodes, "%s depth %d", position.Fen, depth)
+		}
 	}
 }
 
+func TestParsePerftEPD(t *testing.T) {
+	positions, err := engine.ParsePerftEPD(strings.NewReader("# comment\n\n8/8/8/8/8/8/8/K6k w - - ;D1 3 ;D2 9\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, []engine.PerftPosition{
+		{Fen: "8/8/8/8/8/8/8/K6k w - - 0 1", ExpectedNodes: map[uint]uint64{1: 3, 2: 9}},
+	}, positions)
+
+	_, err = engine.ParsePerftEPD(strings.NewReader("8/8/8/8/8/8/8/K7 w - - ;D1 3\n"))
+	assert.NotNil(t, err)
+	_, err = engine.ParsePerftEPD(strings.NewReader("8/8/8/8/8/8/8/K6k w - - ;D1 x\n"))
+	assert.NotNil(t, err)
+}
+
+func TestPerftDivideDiff(t *testing.T) {
+	file, err := os.Open("testdata/perft_divide_startpos_d3.txt")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer file.Close()
+	reference, err := engine.ParsePerftDivide(file)
+	assert.Nil(t, err)
+	assert.Len(t, reference, 20)
+
+	b := chess.NewDefaultBoard()
+	assert.Empty(t, engine.PerftDivideDiff(b, 3, reference))
+
+	reference["e2e4"] = 601
+	reference["e1g1"] = 10
+	delete(reference, "a2a3")
+	diffs := engine.PerftDivideDiff(b, 3, reference)
+	assert.Equal(t, []engine.PerftDiff{
+		{Move: "a2a3", Nodes: 380, Generated: true},
+		{Move: "e1g1", ExpectedNodes: 10, InReference: true},
+		{Move: "e2e4", Nodes: 600, ExpectedNodes: 601, Generated: true, InReference: true},
+	}, diffs)
+	assert.Equal(t, "a2a3: 380 (not in reference)", diffs[0].String())
+	assert.Equal(t, "e1g1: not generated (expected 10)", diffs[1].String())
+	assert.Equal(t, "e2e4: 600 (expected 601)", diffs[2].String())
+}
+
 func BenchmarkPerftInitialBoard(b *testing.B) {
 	initialBoard := chess.NewDefaultBoard()
 	for i := 0; i < b.N; i++ {
 		engine.Perft(initialBoard, 4)
 	}
 }
+
+func BenchmarkPerftKiwipete(b *testing.B) {
+	board := chess.FenToBoard(kiwipete)
+	for i := 0; i < b.N; i++ {
+		engine.Perft(board, 3)
+	}
+}
+
+func BenchmarkPerftPosition3(b *testing.B) {
+	board := chess.FenToBoard(position3)
+	for i := 0; i < b.N; i++ {
+		engine.Perft(board, 4)
+	}
+}
+
+func BenchmarkIsKingInCheck(b *testing.B) {
+	board := chess.FenToBoard(kiwipete)
+	for i := 0; i < b.N; i++ {
+		board.IsKingInCheck()
+	}
+}
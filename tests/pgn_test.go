@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"gce/pkg/chess"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckmateResult(t *testing.T) {
+	b := chess.NewDefaultBoard()
+	makeMoves(t, b, "f2f3", "e7e5", "g2g4", "d8h4")
+	assert.Equal(t, "1. f3 e5 2. g4 Qh4# {Black wins by checkmate} 0-1", b.GetMoveListInNotation())
+	assert.Contains(t, b.PGN(), `[Result "0-1"]`)
+	assert.Equal(t, chess.NoResult, b.Ctx.Result, "Printing the game doesn't change the result")
+
+	assert.True(t, b.IsMated())
+	assert.Equal(t, chess.NoResult, b.Ctx.Result, "Queries don't change the result")
+	assert.Equal(t, chess.BlackWin, b.UpdateResult())
+	assert.Equal(t, chess.Checkmate, b.Ctx.Termination)
+}
+
+func TestStalemateResult(t *testing.T) {
+	b := chess.FenToBoard("k7/8/8/2Q5/8/8/8/7K w - - 0 1")
+	makeMoves(t, b, "c5b6")
+	assert.True(t, b.IsDraw())
+	assert.Equal(t, chess.Draw, b.UpdateResult())
+	assert.Equal(t, chess.Stalemate, b.Ctx.Termination)
+
+	pgn := b.PGN()
+	assert.Contains(t, pgn, `[Result "1/2-1/2"]`)
+	assert.Contains(t, pgn, `[SetUp "1"]`)
+	assert.Contains(t, pgn, `[FEN "k7/8/8/2Q5/8/8/8/7K w - - 0 1"]`)
+	assert.Contains(t, pgn, `[Termination "stalemate"]`)
+	assert.True(t, strings.HasSuffix(pgn, "\n1. Qb6 {Draw by stalemate} 1/2-1/2\n"), pgn)
+}
+
+func TestResignation(t *testing.T) {
+	b := chess.NewDefaultBoard()
+	makeMoves(t, b, "e2e4")
+	b.Resign(false)
+	assert.Equal(t, chess.WhiteWin, b.Ctx.Result)
+	assert.Equal(t, "1. e4 {White wins by resignation} 1-0", b.GetMoveListInNotation())
+
+	// On the opponent's turn
+	b = chess.NewDefaultBoard()
+	makeMoves(t, b, "e2e4")
+	b.Resign(true)
+	assert.Equal(t, chess.BlackWin, b.Ctx.Result)
+	assert.Equal(t, "1. e4 {Black wins by resignation} 0-1", b.GetMoveListInNotation())
+}
+
+func TestPGNOngoingGame(t *testing.T) {
+	b := chess.FenToBoard("4k3/8/8/8/8/8/P7/4K3 b - - 0 40")
+	makeMoves(t, b, "e8d7", "a2a4", "d7c7")
+	expected := `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "?"]
+[Black "?"]
+[Result "*"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/P7/4K3 b - - 0 40"]
+
+40... Kd7 41. a4 Kc7 *
+`
+	assert.Equal(t, expected, b.PGN())
+	// Generating the PGN must not change the board
+	assert.Equal(t, "8/2k5/8/8/P7/8/8/4K3 w - - 1 42", b.ToFEN())
+}
+
+func TestPGNClaimableRepetition(t *testing.T) {
+	b := chess.NewDefaultBoard()
+	knightsShuffle := []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+	makeMoves(t, b, knightsShuffle...)
+	makeMoves(t, b, knightsShuffle...)
+	assert.True(t, b.CanClaimDraw())
+
+	// Nobody claims the draw and the game goes on
+	makeMoves(t, b, "e2e4", "e7e5")
+	pgn := b.PGN()
+	assert.Contains(t, pgn, `[Result "*"]`)
+	assert.NotContains(t, pgn, "Termination")
+	assert.True(t, strings.HasSuffix(pgn, " 5. e4 e5 *\n"), pgn)
+
+	b = chess.NewDefaultBoard()
+	makeMoves(t, b, knightsShuffle...)
+	makeMoves(t, b, knightsShuffle...)
+	assert.True(t, b.ClaimDraw())
+	pgn = b.PGN()
+	assert.Contains(t, pgn, `[Result "1/2-1/2"]`)
+	assert.Contains(t, pgn, `[Termination "threefold repetition"]`)
+	assert.True(t, strings.HasSuffix(pgn, " 4. Ng1 Ng8 {Draw by threefold repetition} 1/2-1/2\n"), pgn)
+}
+
+func TestPGNPromotion(t *testing.T) {
+	b := chess.FenToBoard("4k3/P7/8/8/8/8/8/4K3 b - - 0 40")
+	makeMoves(t, b, "e8d7", "a7a8q", "d7c7")
+	assert.True(t, strings.HasSuffix(b.PGN(), "\n40... Kd7 41. a8=Q Kc7 *\n"))
+	assert.Contains(t, b.PGN(), `[FEN "4k3/P7/8/8/8/8/8/4K3 b - - 0 40"]`)
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"gce/pkg/chess"
+	"gce/pkg/engine"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuiescenceAvoidsDefendedCapture(t *testing.T) {
+	config := engine.NewSearchConfig()
+	// The pawn on d5 is defended, taking it with the queen loses the queen
+	fen := "4k3/8/4p3/3p4/8/8/8/3QK3 w - - 0 1"
+
+	config.Options.Quiescence = false
+	report := engine.AnalysisByDepth(chess.FenToBoard(fen), 1, config)
+	assert.Equal(t, "d1d5", report.Moves[0].StockfishString())
+
+	config.Options.Quiescence = true
+	report = engine.AnalysisByDepth(chess.FenToBoard(fen), 1, config)
+	assert.NotEqual(t, "d1d5", report.Moves[0].StockfishString())
+	assert.Less(t, report.Evaluation, 900)
+}
+
+func TestQuiescenceFindsWinningCapture(t *testing.T) {
+	config := engine.NewSearchConfig()
+	// The rook is worth more, but it's defended, the knight isn't
+	fen := "4k3/8/2p5/3r4/n7/8/8/3QK3 w - - 0 1"
+
+	config.Options.Quiescence = false
+	report := engine.AnalysisByDepth(chess.FenToBoard(fen), 1, config)
+	assert.Equal(t, "d1d5", report.Moves[0].StockfishString())
+
+	config.Options.Quiescence = true
+	for _, checks := range []bool{false, true} {
+		config.Options.QuiescenceChecks = checks
+		report := engine.AnalysisByDepth(chess.FenToBoard(fen), 1, config)
+		assert.Equal(t, "d1a4", report.Moves[0].StockfishString())
+	}
+}
+
+func TestDeltaPruningBound(t *testing.T) {
+	// The pruned captures must be part of the upper bound stored in the transposition table
+	options := engine.DefaultSearchOptions
+	disablePruning(&options)
+	withoutTT, withTT := compareTranspositionTable(options, 4)
+	for i, fen := range searchTestPositions {
+		assert.Equal(t, withoutTT[i].Evaluation, withTT[i].Evaluation, fen)
+	}
+}
+
+func TestMoveSortingMvvLva(t *testing.T) {
+	// The d5 queen can be captured by the pawn, the knight and the queen
+	b := chess.FenToBoard("4k3/8/2r5/3q4/4P3/2N5/8/3QK3 w - - 0 1")
+	moves := engine.MoveSlice(b.AllLegalMoves())
+	sort.Sort(moves)
+
+	notations := []string{}
+	for _, move := range moves[:3] {
+		notations = append(notations, move.StockfishString())
+	}
+	assert.Equal(t, []string{"e4d5", "c3d5", "d1d5"}, notations)
+	assert.False(t, moves[3].IsCapture)
+}
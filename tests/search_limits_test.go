@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"gce/pkg/chess"
+	"gce/pkg/engine"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeBudget(t *testing.T) {
+	limits := engine.SearchLimits{MoveTime: time.Second}
+	assert.Equal(t, time.Second, limits.TimeBudget(true))
+
+	limits = engine.SearchLimits{WhiteTime: 60 * time.Second, BlackTime: time.Second, WhiteInc: 2 * time.Second}
+	assert.Equal(t, 3*time.Second, limits.TimeBudget(true))
+	// Never more than half of the remaining time
+	limits.MovesToGo = 1
+	assert.Equal(t, 30*time.Second, limits.TimeBudget(true))
+	assert.Equal(t, 500*time.Millisecond, limits.TimeBudget(false))
+
+	assert.Equal(t, time.Duration(0), engine.SearchLimits{Infinite: true, MoveTime: time.Second}.TimeBudget(true))
+}
+
+func TestAnalysisByTimeDepthLimit(t *testing.T) {
+	b := chess.FenToBoard(position3)
+	depths := []uint{}
+	report := engine.AnalysisByTime(b, engine.SearchLimits{Depth: 3}, engine.NewSearchConfig(), nil, func(report engine.AnalysisReport) {
+		depths = append(depths, report.Depth)
+	})
+	assert.Equal(t, []uint{1, 2, 3}, depths)
+	assert.Equal(t, uint(3), report.Depth)
+	assert.NotEmpty(t, report.Moves)
+	assert.Equal(t, position3, b.ToFEN())
+
+	byDepth := engine.AnalysisByDepth(chess.FenToBoard(position3), 3, engine.NewSearchConfig())
+	assert.Equal(t, byDepth.Evaluation, report.Evaluation)
+}
+
+func TestAnalysisByTimeNodeLimit(t *testing.T) {
+	b := chess.FenToBoard(kiwipete)
+	report := engine.AnalysisByTime(b, engine.SearchLimits{Nodes: 5000}, engine.NewSearchConfig(), nil, nil)
+	assert.NotEmpty(t, report.Moves)
+	assert.GreaterOrEqual(t, report.Depth, uint(1))
+	// The search is aborted as soon as the limit is reached
+	assert.LessOrEqual(t, report.Stats.Nodes, uint64(5000+len(b.AllLegalMoves())))
+	assert.Equal(t, kiwipete, b.ToFEN())
+}
+
+func TestAnalysisByTimeMoveTime(t *testing.T) {
+	b := chess.FenToBoard(kiwipete)
+	startTime := time.Now()
+	report := engine.AnalysisByTime(b, engine.SearchLimits{MoveTime: 200 * time.Millisecond}, engine.NewSearchConfig(), nil, nil)
+	assert.Less(t, time.Since(startTime), time.Second)
+	assert.NotEmpty(t, report.Moves)
+	assert.Equal(t, kiwipete, b.ToFEN())
+}
+
+func TestAnalysisByTimeStop(t *testing.T) {
+	b := chess.FenToBoard(kiwipete)
+	stopCh := make(chan struct{})
+	time.AfterFunc(100*time.Millisecond, func() { close(stopCh) })
+
+	startTime := time.Now()
+	report := engine.AnalysisByTime(b, engine.SearchLimits{Infinite: true}, engine.NewSearchConfig(), stopCh, nil)
+	assert.Less(t, time.Since(startTime), time.Second)
+	assert.NotEmpty(t, report.Moves)
+	assert.Equal(t, kiwipete, b.ToFEN())
+}
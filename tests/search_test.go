@@ -0,0 +1,292 @@
+package tests
+
+import (
+	"gce/pkg/chess"
+	"gce/pkg/engine"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMateIn(t *testing.T) {
+	testCases := []struct {
+		fen        string
+		depth      uint
+		mateIn     int
+		evaluation int
+	}{
+		// Mate in 1 is preferred, even when searching deeper
+		{"7k/5Q2/6K1/8/8/8/8/8 w - - 0 1", 3, 1, engine.MateScore - 1},
+		{"8/8/8/8/8/6k1/5q2/7K b - - 0 1", 3, -1, -engine.MateScore + 1},
+		// Ra6 and b7 mate
+		{"kbK5/pp6/1P6/8/8/8/8/R7 w - - 0 1", 3, 2, engine.MateScore - 3},
+		// Already mated
+		{"7k/6Q1/6K1/8/8/8/8/8 b - - 0 1", 1, 0, engine.MateScore},
+		{startPosition, 2, 0, 0},
+	}
+	for _, tc := range testCases {
+		report := engine.AnalysisByDepth(chess.FenToBoard(tc.fen), tc.depth, engine.NewSearchConfig())
+		assert.Equal(t, tc.mateIn, report.MateIn, tc.fen)
+		if tc.mateIn != 0 || tc.evaluation != 0 {
+			assert.Equal(t, tc.evaluation, report.Evaluation, tc.fen)
+		}
+	}
+}
+
+func TestDrawnRoot(t *testing.T) {
+	testCases := []struct {
+		name string
+		fen  string
+	}{
+		{"Stalemate", "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1"},
+		{"Insufficient material", "8/8/8/4k3/8/8/8/4K2B w - - 0 1"},
+		{"75 moves rule", "8/8/4k3/8/8/3K4/7R/8 w - - 150 105"},
+	}
+	for _, tc := range testCases {
+		report := engine.AnalysisByDepth(chess.FenToBoard(tc.fen), 3, engine.NewSearchConfig())
+		assert.Equal(t, 0, report.Evaluation, tc.name)
+		assert.Empty(t, report.Moves, tc.name)
+	}
+
+	// The game goes on if a draw can be claimed but nobody claims it
+	b := chess.NewDefaultBoard()
+	knightsShuffle := []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+	makeMoves(t, b, knightsShuffle...)
+	makeMoves(t, b, knightsShuffle...)
+	report := engine.AnalysisByDepth(b, 3, engine.NewSearchConfig())
+	assert.NotEmpty(t, report.Moves)
+}
+
+func TestEvaluationString(t *testing.T) {
+	assert.Equal(t, "+1.50", engine.AnalysisReport{Evaluation: 150}.EvaluationString())
+	assert.Equal(t, "-0.05", engine.AnalysisReport{Evaluation: -5}.EvaluationString())
+	assert.Equal(t, "#3", engine.AnalysisReport{Evaluation: engine.MateScore - 5, MateIn: 3}.EvaluationString())
+	assert.Equal(t, "#-2", engine.AnalysisReport{Evaluation: -engine.MateScore + 4, MateIn: -2}.EvaluationString())
+}
+
+func TestSearchStats(t *testing.T) {
+	report := engine.AnalysisByDepth(chess.FenToBoard(kiwipete), 2, engine.NewSearchConfig())
+	stats := report.Stats
+	assert.Greater(t, stats.QNodes, uint64(0))
+	assert.Greater(t, stats.Nodes, stats.QNodes)
+	assert.Greater(t, stats.Cutoffs, uint64(0))
+	assert.Greater(t, stats.TTProbes, uint64(0))
+	assert.GreaterOrEqual(t, stats.SelDepth, report.Depth)
+	assert.Greater(t, stats.Elapsed, time.Duration(0))
+}
+
+func TestProgressReporter(t *testing.T) {
+	var mu sync.Mutex
+	reports := []engine.SearchStats{}
+	config := engine.NewSearchConfig()
+	config.ProgressInterval = 10 * time.Millisecond
+	config.Progress = engine.ProgressFunc(func(stats engine.SearchStats) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, stats)
+	})
+	report := engine.AnalysisByTime(chess.FenToBoard(kiwipete), engine.SearchLimits{MoveTime: 200 * time.Millisecond}, config, nil, nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.NotEmpty(t, reports)
+	for i := 1; i < len(reports); i++ {
+		assert.GreaterOrEqual(t, reports[i].Nodes, reports[i-1].Nodes)
+	}
+	assert.LessOrEqual(t, reports[len(reports)-1].Nodes, report.Stats.Nodes)
+}
+
+func TestConcurrentSearches(t *testing.T) {
+	// Every search has its own config, so searches with different options don't interfere
+	withQuiescence := engine.SearchConfig{Options: engine.DefaultSearchOptions}
+	withoutQuiescence := withQuiescence
+	withoutQuiescence.Options.Quiescence = false
+	configs := []engine.SearchConfig{withQuiescence, withoutQuiescence}
+
+	expected := make([]engine.AnalysisReport, len(configs))
+	for i, config := range configs {
+		expected[i] = engine.AnalysisByDepth(chess.FenToBoard(position4), 3, config)
+	}
+	reports := make([]engine.AnalysisReport, len(configs))
+	var wg sync.WaitGroup
+	for i, config := range configs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reports[i] = engine.AnalysisByDepth(chess.FenToBoard(position4), 3, config)
+		}()
+	}
+	wg.Wait()
+
+	for i := range configs {
+		assert.Equal(t, expected[i].Evaluation, reports[i].Evaluation)
+		assert.Equal(t, expected[i].Moves, reports[i].Moves)
+		assert.Equal(t, expected[i].Stats.Nodes, reports[i].Stats.Nodes)
+	}
+}
+
+func TestPrincipalVariationSearch(t *testing.T) {
+	without, with := compareOption(3, func(o *engine.SearchOptions, enabled bool) { o.PVS = enabled })
+	for i, fen := range searchTestPositions {
+		assert.Equal(t, uint64(0), without[i].Stats.Researches, fen)
+		assert.Equal(t, without[i].Evaluation, with[i].Evaluation, fen)
+		assert.Equal(t, without[i].Moves[0], with[i].Moves[0], fen)
+	}
+}
+
+func TestAspirationWindows(t *testing.T) {
+	// A tiny window usually fails, but the evaluation is the same after searching again
+	fullWindow, aspiration := compareOption(3, func(o *engine.SearchOptions, enabled bool) {
+		o.AspirationWindow = 0
+		if enabled {
+			o.AspirationWindow = 1
+		}
+	})
+	fails := uint64(0)
+	for i, fen := range searchTestPositions {
+		assert.Equal(t, uint64(0), fullWindow[i].Stats.FailHighs+fullWindow[i].Stats.FailLows, fen)
+		assert.Equal(t, fullWindow[i].Evaluation, aspiration[i].Evaluation, fen)
+		fails += aspiration[i].Stats.FailHighs + aspiration[i].Stats.FailLows
+	}
+	assert.Greater(t, fails, uint64(0))
+}
+
+func TestOrderingHeuristics(t *testing.T) {
+	// The order of the moves doesn't change the evaluation, only how many nodes are searched
+	without, with := compareOption(4, func(o *engine.SearchOptions, enabled bool) { o.OrderingHeuristics = enabled })
+	for i, fen := range searchTestPositions {
+		assert.Equal(t, without[i].Evaluation, with[i].Evaluation, fen)
+	}
+	assert.Less(t, totalNodes(with), totalNodes(without))
+}
+
+func TestPruning(t *testing.T) {
+	searchNodes := func(options engine.SearchOptions) uint64 {
+		reports := searchPositions(options, 5)
+		for i, fen := range searchTestPositions {
+			assert.NotEmpty(t, reports[i].Moves, fen)
+		}
+		return totalNodes(reports)
+	}
+
+	noPruning := engine.DefaultSearchOptions
+	disablePruning(&noPruning)
+	nodesWithout := searchNodes(noPruning)
+	for name, enable := range map[string]func(*engine.SearchOptions){
+		"NullMove":        func(o *engine.SearchOptions) { o.NullMove = true },
+		"LMR":             func(o *engine.SearchOptions) { o.LMR = true },
+		"ReverseFutility": func(o *engine.SearchOptions) { o.ReverseFutility = true },
+		"Futility":        func(o *engine.SearchOptions) { o.Futility = true },
+		"SEE":             func(o *engine.SearchOptions) { o.SEE = true },
+	} {
+		options := noPruning
+		enable(&options)
+		assert.Less(t, searchNodes(options), nodesWithout, name)
+	}
+	assert.Less(t, searchNodes(engine.DefaultSearchOptions), nodesWithout)
+}
+
+func TestFutilityPruningBound(t *testing.T) {
+	// The pruned quiet moves must be part of the upper bounds stored in the transposition table,
+	// so they aren't lower than the evaluation found by searching all the moves
+	exactOptions := engine.DefaultSearchOptions
+	disablePruning(&exactOptions)
+	options := exactOptions
+	options.Futility = true
+	for _, fen := range searchTestPositions {
+		board := chess.FenToBoard(fen)
+		tt := engine.NewTranspositionTable(1)
+		engine.AnalysisByDepth(board, 3, engine.SearchConfig{Options: options, TT: tt})
+		// Only the nodes at ply 1 and 2 have a low enough depth to be pruned
+		assertUpperBoundsThroughTree(t, board, tt, exactOptions, 2)
+	}
+}
+
+// assertUpperBoundsThroughTree checks that the upper bounds stored in the transposition table for the positions
+// reachable in depth plies aren't lower than the evaluation of searching them with options.
+func assertUpperBoundsThroughTree(t *testing.T, b *chess.Board, tt *engine.TranspositionTable, options engine.SearchOptions, depth int) {
+	if entry, found := tt.Probe(b.Hash()); found && entry.Bound == engine.UpperBound {
+		evaluation := engine.AnalysisByDepth(b, entry.Depth, engine.SearchConfig{Options: options}).Evaluation
+		if !b.Ctx.WhiteTurn {
+			evaluation = -evaluation
+		}
+		assert.LessOrEqual(t, evaluation, entry.Evaluation, b.ToFEN())
+	}
+	if depth == 0 {
+		return
+	}
+	for _, move := range b.AllLegalMoves() {
+		b.MakeLegalMove(move)
+		assertUpperBoundsThroughTree(t, b, tt, options, depth-1)
+		b.UndoMove()
+	}
+}
+
+// disablePruning disables the pruning that depends on the window, which can change the evaluation.
+func disablePruning(options *engine.SearchOptions) {
+	options.NullMove, options.LMR, options.ReverseFutility, options.Futility = false, false, false, false
+	options.SEE = false
+}
+
+// Positions with many captures, checks and promotions, used to compare the search options
+var searchTestPositions = []string{position3, position4, position5}
+
+// searchPositions searches every position of searchTestPositions with iterative deepening up to depth,
+// without transposition table, so the results of a position don't depend on the previous ones.
+func searchPositions(options engine.SearchOptions, depth uint) []engine.AnalysisReport {
+	reports := make([]engine.AnalysisReport, len(searchTestPositions))
+	for i, fen := range searchTestPositions {
+		limits := engine.SearchLimits{Depth: depth}
+		reports[i] = engine.AnalysisByTime(chess.FenToBoard(fen), limits, engine.SearchConfig{Options: options}, nil, nil)
+	}
+	return reports
+}
+
+// compareOption searches the positions of searchTestPositions with an option disabled and enabled by set.
+// The pruning is disabled, so the option is the only difference between both searches.
+func compareOption(depth uint, set func(options *engine.SearchOptions, enabled bool)) (without, with []engine.AnalysisReport) {
+	options := engine.DefaultSearchOptions
+	disablePruning(&options)
+	set(&options, false)
+	without = searchPositions(options, depth)
+	set(&options, true)
+	with = searchPositions(options, depth)
+	return without, with
+}
+
+// compareTranspositionTable searches the positions of searchTestPositions to depth, without and with a new
+// transposition table. A cutoff with an entry of the table only gives the same evaluation as searching the position
+// again if the bound stored in the entry is right, which isn't true for fail soft values that ignore the pruned moves.
+// Iterative deepening isn't used, since the move ordering it gives hides most of the wrong bounds.
+func compareTranspositionTable(options engine.SearchOptions, depth uint) (withoutTT, withTT []engine.AnalysisReport) {
+	for _, fen := range searchTestPositions {
+		config := engine.SearchConfig{Options: options}
+		withoutTT = append(withoutTT, engine.AnalysisByDepth(chess.FenToBoard(fen), depth, config))
+		config.TT = engine.NewTranspositionTable(engine.DefaultTTSizeMB)
+		withTT = append(withTT, engine.AnalysisByDepth(chess.FenToBoard(fen), depth, config))
+	}
+	return withoutTT, withTT
+}
+
+func totalNodes(reports []engine.AnalysisReport) uint64 {
+	nodes := uint64(0)
+	for _, report := range reports {
+		nodes += report.Stats.Nodes
+	}
+	return nodes
+}
+
+func TestNullMoveZugzwang(t *testing.T) {
+	config := engine.SearchConfig{Options: engine.DefaultSearchOptions}
+
+	// In pawn endgames the side to move is often in zugzwang, so the null move pruning isn't used
+	// and the search is exactly the same
+	fen := "8/8/4k3/3p4/3P4/4K3/8/8 w - - 0 1"
+	withNullMove := engine.AnalysisByDepth(chess.FenToBoard(fen), 6, config)
+	config.Options.NullMove = false
+	withoutNullMove := engine.AnalysisByDepth(chess.FenToBoard(fen), 6, config)
+	assert.Equal(t, withoutNullMove.Stats.Nodes, withNullMove.Stats.Nodes)
+	assert.Equal(t, withoutNullMove.Evaluation, withNullMove.Evaluation)
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"gce/pkg/chess"
+	"gce/pkg/engine"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSEE(t *testing.T) {
+	testCases := []struct {
+		name string
+		fen  string
+		move string
+		see  int
+	}{
+		{"Undefended pawn", "1k1r4/1pp4p/p7/4p3/8/P5P1/1PP4P/2K1R3 w - - 0 1", "e1e5", 100},
+		{"Queen takes defended pawn", "4k3/8/2p5/3p4/8/8/8/3QK3 w - - 0 1", "d1d5", -800},
+		{"Pawn takes queen", "4k3/8/8/3q4/4P3/8/8/4K3 w - - 0 1", "e4d5", 900},
+		{"Equal trade", "4k3/8/4n3/3p4/8/2N5/8/4K3 w - - 0 1", "c3d5", 100},
+		{"Sequence with x-rays", "1k1r3q/1ppn3p/p4b2/4p3/8/P2N2P1/1PP1R1BP/2K1Q3 w - - 0 1", "d3e5", -200},
+		{"Defended by an x-ray", "3r2k1/3r4/8/3p4/8/8/3R4/3R2K1 w - - 0 1", "d2d5", -400},
+		{"Attacked by an x-ray", "6k1/3r4/8/3p4/8/8/3R4/3R2K1 w - - 0 1", "d2d5", 100},
+		{"Queen behind a bishop", "6k1/8/2p5/3p4/8/8/6B1/K6Q w - - 0 1", "g2d5", -100},
+		{"King recaptures", "8/8/4k3/3p4/8/8/8/3R2K1 w - - 0 1", "d1d5", -400},
+		{"King can't recapture a defended piece", "8/8/4k3/3p4/8/5B2/8/3R2K1 w - - 0 1", "d1d5", 100},
+		{"En passant", "4k3/8/8/3Pp3/8/8/8/4K3 w - e6 0 1", "d5e6", 100},
+		{"Promotion", "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8q", 800},
+		{"Promotion capture recaptured", "2r5/1P1k4/8/8/8/8/8/4K3 w - - 0 1", "b7c8q", 400},
+		{"Quiet move to an attacked square", "4k3/8/8/8/4p3/8/8/4K1N1 w - - 0 1", "g1f3", -300},
+		{"Quiet move to a safe square", "4k3/8/8/8/4p3/8/8/4K1N1 w - - 0 1", "g1h3", 0},
+		{"Black captures", "4k3/8/8/3p4/4N3/5P2/8/4K3 b - - 0 1", "d5e4", 200},
+		{"Black rook takes a defended pawn", "4k3/8/8/3r4/8/3P4/2P5/4K3 b - - 0 1", "d5d3", -400},
+	}
+	for _, tc := range testCases {
+		board := chess.FenToBoard(tc.fen)
+		move, err := board.ParseStockfishMove(tc.move)
+		if !assert.Nil(t, err, tc.name) {
+			continue
+		}
+		assert.Equal(t, tc.see, engine.SEE(board, move), tc.name)
+	}
+}
+
+func TestSEEQueenDoesntTakeDefendedPawn(t *testing.T) {
+	board := chess.FenToBoard("4k3/8/2p5/3p4/8/8/8/3QK3 w - - 0 1")
+	report := engine.AnalysisByDepth(board, 2, engine.NewSearchConfig())
+	if !assert.NotEmpty(t, report.Moves) {
+		return
+	}
+	assert.NotEqual(t, "d1d5", report.Moves[0].StockfishString())
+}
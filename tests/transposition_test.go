@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"gce/pkg/chess"
+	"gce/pkg/engine"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranspositionTableProbeAndStore(t *testing.T) {
+	tt := engine.NewTranspositionTable(1)
+	b := chess.NewDefaultBoard()
+	move := b.AllLegalMoves()[0]
+
+	_, found := tt.Probe(b.Hash())
+	assert.False(t, found)
+
+	tt.Store(b.Hash(), 3, engine.LowerBound, 150, move)
+	entry, found := tt.Probe(b.Hash())
+	assert.True(t, found)
+	assert.Equal(t, uint(3), entry.Depth)
+	assert.Equal(t, engine.LowerBound, entry.Bound)
+	assert.Equal(t, 150, entry.Evaluation)
+	assert.True(t, entry.IsBestMove(move))
+	assert.False(t, entry.IsBestMove(b.AllLegalMoves()[1]))
+
+	tt.Store(b.Hash(), 3, engine.UpperBound, -engine.MateScore+5, chess.Move{})
+	entry, _ = tt.Probe(b.Hash())
+	assert.Equal(t, -engine.MateScore+5, entry.Evaluation)
+	assert.Equal(t, engine.UpperBound, entry.Bound)
+	assert.False(t, entry.IsBestMove(move))
+
+	// A shallower result of the same position doesn't replace a deeper one
+	tt.Store(b.Hash(), 1, engine.ExactBound, 50, move)
+	entry, _ = tt.Probe(b.Hash())
+	assert.Equal(t, uint(3), entry.Depth)
+	tt.Store(b.Hash(), 4, engine.ExactBound, 50, move)
+	entry, _ = tt.Probe(b.Hash())
+	assert.Equal(t, uint(4), entry.Depth)
+
+	tt.Clear()
+	_, found = tt.Probe(b.Hash())
+	assert.False(t, found)
+	assert.Equal(t, uint64(0), tt.Hashfull())
+}
+
+func TestTranspositionTablePromotions(t *testing.T) {
+	tt := engine.NewTranspositionTable(1)
+	b := chess.FenToBoard("8/1P6/8/8/8/8/8/K1k5 w - - 0 1")
+	promotions := []chess.Move{}
+	for _, move := range b.AllLegalMoves() {
+		if move.IsPromotion {
+			promotions = append(promotions, move)
+		}
+	}
+	assert.Len(t, promotions, 4)
+
+	for _, promotion := range promotions {
+		tt.Store(b.Hash(), 1, engine.ExactBound, 0, promotion)
+		entry, _ := tt.Probe(b.Hash())
+		for _, other := range promotions {
+			assert.Equal(t, promotion == other, entry.IsBestMove(other))
+		}
+	}
+}
+
+func TestTranspositionTableConcurrentAccess(t *testing.T) {
+	tt := engine.NewTranspositionTable(1)
+	var wg sync.WaitGroup
+	for worker := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 20_000 {
+				// Every worker writes different data for the same hashes
+				hash := uint64(i%5000) * 0x9E3779B97F4A7C15
+				tt.Store(hash, uint(worker), engine.ExactBound, int(hash%1000)*10+worker, chess.Move{})
+				if entry, found := tt.Probe(hash); found {
+					// A torn entry would mix the data of different workers
+					assert.Equal(t, int(hash%1000)*10+int(entry.Depth), entry.Evaluation)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTranspositionTableDisabled(t *testing.T) {
+	tt := engine.NewTranspositionTable(0)
+	b := chess.NewDefaultBoard()
+	tt.Store(b.Hash(), 1, engine.ExactBound, 0, chess.Move{})
+	_, found := tt.Probe(b.Hash())
+	assert.False(t, found)
+}
+
+func TestSearchWithTranspositionTable(t *testing.T) {
+	// With all the pruning enabled, as the engine plays
+	withoutTT, withTT := compareTranspositionTable(engine.DefaultSearchOptions, 5)
+	for i, fen := range searchTestPositions {
+		assert.Equal(t, uint64(0), withoutTT[i].Stats.TTHits, fen)
+		assert.Greater(t, withTT[i].Stats.TTHits, uint64(0), fen)
+		assert.Equal(t, withoutTT[i].Evaluation, withTT[i].Evaluation, fen)
+		assert.NotEmpty(t, withTT[i].Moves, fen)
+	}
+	// The table can make a position search more nodes, if a cutoff changes which moves are pruned
+	assert.Less(t, totalNodes(withTT), totalNodes(withoutTT))
+}
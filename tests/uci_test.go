@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"bytes"
+	"gce/pkg/uci"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUciHandshake(t *testing.T) {
+	out := &bytes.Buffer{}
+	handler := uci.NewHandler(out)
+	err := handler.Run(strings.NewReader("uci\nisready\nquit\n"))
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, "id name gce", lines[0])
+	assert.Equal(t, "uciok", lines[len(lines)-2])
+	assert.Equal(t, "readyok", lines[len(lines)-1])
+}
+
+// syncBuffer is a bytes.Buffer that can be read while the search goroutine writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.Write(p)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.String()
+}
+
+// uciSession sends the commands to a new handler and returns its output.
+// After every "go" command, it waits for the bestmove before sending the next command.
+func uciSession(t *testing.T, commands ...string) string {
+	out := &syncBuffer{}
+	handler := uci.NewHandler(out)
+	for _, command := range commands {
+		bestMoves := strings.Count(out.String(), "bestmove ")
+		handler.HandleCommand(command)
+		if !strings.HasPrefix(command, "go") {
+			continue
+		}
+
+		deadline := time.Now().Add(10 * time.Second)
+		for strings.Count(out.String(), "bestmove ") == bestMoves {
+			if time.Now().After(deadline) {
+				t.Fatalf("No bestmove after %q", command)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	handler.HandleCommand("quit")
+	return out.String()
+}
+
+func TestUciGoDepth(t *testing.T) {
+	// Mate in one with the queen, there are three of them
+	output := uciSession(t, "position fen 7k/5Q2/6K1/8/8/8/8/8 w - - 0 1", "go depth 2")
+	assert.Regexp(t, `info depth 1 seldepth \d+ score mate 1 nodes \d+`, output)
+	assert.Regexp(t, `info depth 2 seldepth \d+ score mate 1 nodes \d+`, output)
+	assert.Regexp(t, `bestmove f7(f8|g7|h7)\n`, output)
+}
+
+func TestUciGoRepeatedPosition(t *testing.T) {
+	// Threefold repetition that nobody claimed, the game goes on
+	output := uciSession(t, "position startpos moves g1f3 g8f6 f3g1 f6g8 g1f3 g8f6 f3g1 f6g8", "go depth 2")
+	assert.Regexp(t, `bestmove [a-h][1-8][a-h][1-8]\n`, output)
+}
+
+func TestUciGoInsufficientMaterial(t *testing.T) {
+	// The game is drawn even if there are legal moves
+	output := uciSession(t, "position fen 8/8/8/4k3/8/8/8/4K2B w - - 0 1", "go depth 3")
+	assert.Contains(t, output, "bestmove 0000\n")
+}
+
+func TestUciGoLimits(t *testing.T) {
+	startTime := time.Now()
+	output := uciSession(
+		t,
+		"position fen "+kiwipete,
+		"go movetime 100",
+		"go nodes 1000",
+		"go wtime 1000 btime 1000 winc 10 binc 10",
+	)
+	assert.Less(t, time.Since(startTime), 2*time.Second)
+	assert.Equal(t, 3, strings.Count(output, "bestmove "))
+}
+
+func TestUciPositionWithMoves(t *testing.T) {
+	out := &bytes.Buffer{}
+	handler := uci.NewHandler(out)
+	// Scholar's mate, black is mated so there's no move to play
+	input := "position startpos moves e2e4 e7e5 f1c4 b8c6 d1h5 g8f6 h5f7\ngo depth 1\nquit\n"
+	err := handler.Run(strings.NewReader(input))
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "bestmove 0000\n")
+
+	out.Reset()
+	err = handler.Run(strings.NewReader("position startpos moves e2e5\nquit\n"))
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "info string Invalid move: e2e5")
+
+	out.Reset()
+	err = handler.Run(strings.NewReader("position fen 8/8/8/8/8/8/8/8 w - - 0 1\nquit\n"))
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "info string Invalid FEN")
+}
+
+func TestUciSetOption(t *testing.T) {
+	out := &bytes.Buffer{}
+	handler := uci.NewHandler(out)
+	input := "uci\nsetoption name Hash value 1\nsetoption name Hash value abc\nsetoption name Foo value 1\n" +
+		"setoption name Quiescence value false\nsetoption name NullMove value false\nsetoption name LMR value maybe\nquit\n"
+	err := handler.Run(strings.NewReader(input))
+	assert.Nil(t, err)
+
+	output := out.String()
+	assert.Contains(t, output, "option name Hash type spin default 16 min 0 max 1024\n")
+	assert.NotContains(t, output, "info string Invalid Hash value: 1\n")
+	assert.Contains(t, output, "info string Invalid Hash value: abc\n")
+	assert.Contains(t, output, "info string Unknown option: Foo\n")
+	assert.Contains(t, output, "option name NullMove type check default true\n")
+	assert.Contains(t, output, "info string Invalid LMR value: maybe\n")
+	options := handler.SearchConfig().Options
+	assert.False(t, options.Quiescence)
+	assert.False(t, options.NullMove)
+	assert.True(t, options.LMR)
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"gce/pkg/chess"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashIncrementalMatchesFromScratch(t *testing.T) {
+	testCases := []struct {
+		fen   string
+		depth int
+	}{
+		{startPosition, 3},
+		{kiwipete, 3},
+		{position3, 4},
+		{position4, 3},
+		{position5, 2},
+		{position6, 2},
+	}
+	for _, tc := range testCases {
+		b := chess.FenToBoard(tc.fen)
+		assertHashThroughTree(t, b, tc.depth)
+		assert.Equal(t, tc.fen, b.ToFEN())
+	}
+}
+
+func assertHashThroughTree(t *testing.T, b *chess.Board, depth int) {
+	if !assert.Equal(t, b.ComputeHash(), b.Hash(), b.ToFEN()) {
+		t.FailNow()
+	}
+	if depth == 0 {
+		return
+	}
+
+	for _, move := range b.AllLegalMoves() {
+		hashBefore := b.Hash()
+		b.MakeLegalMove(move)
+		assertHashThroughTree(t, b, depth-1)
+		b.UndoMove()
+		assert.Equal(t, hashBefore, b.Hash())
+	}
+}
+
+func TestHashTransposition(t *testing.T) {
+	playMoves := func(moves ...string) *chess.Board {
+		b := chess.NewDefaultBoard()
+		for _, moveStr := range moves {
+			move, err := b.ParseStockfishMove(moveStr)
+			assert.Nil(t, err)
+			b.MakeLegalMove(move)
+		}
+		return b
+	}
+
+	b1 := playMoves("e2e4", "e7e5", "g1f3", "b8c6")
+	b2 := playMoves("g1f3", "b8c6", "e2e4", "e7e5")
+	assert.Equal(t, b1.Hash(), b2.Hash())
+
+	// Same pieces, but different side to move
+	b3 := playMoves("g1f3", "g8f6", "f3g1")
+	b4 := playMoves("g1f3", "g8f6", "f3g1", "f6g8")
+	assert.NotEqual(t, b3.Hash(), b4.Hash())
+	assert.Equal(t, chess.NewDefaultBoard().Hash(), b4.Hash())
+
+	// En passant square is only hashed if the capture is possible
+	b5 := playMoves("e2e4")
+	b6 := chess.FenToBoard("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+	assert.Equal(t, b5.Hash(), b6.Hash())
+	b7 := playMoves("e2e4", "a7a6", "e4e5", "d7d5")
+	b8 := chess.FenToBoard("rnbqkbnr/1pp1pppp/p7/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq - 0 3")
+	assert.NotEqual(t, b7.Hash(), b8.Hash())
+
+	// Castling rights are part of the hash
+	b9 := playMoves("g1f3", "g8f6", "h1g1", "f6g8", "g1h1", "g8f6")
+	b10 := playMoves("g1f3", "g8f6")
+	assert.NotEqual(t, b9.Hash(), b10.Hash())
+}
+
+func TestNullMove(t *testing.T) {
+	// After e2e4 black could capture en passant if there was a pawn, the null move removes the en passant square
+	b := chess.FenToBoard("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 3")
+	fen, hash := b.ToFEN(), b.Hash()
+
+	b.MakeNullMove()
+	assert.True(t, b.Ctx.WhiteTurn)
+	assert.Equal(t, uint64(0), b.Ctx.EnPassant)
+	assert.Equal(t, b.ComputeHash(), b.Hash())
+	assert.True(t, b.MovesDone[len(b.MovesDone)-1].IsNullMove())
+
+	// The position after a move and a null move is the same as after the move for the other side
+	move, err := b.ParseStockfishMove("g1f3")
+	assert.Nil(t, err)
+	b.MakeLegalMove(move)
+	assert.Equal(t, b.ComputeHash(), b.Hash())
+	b.UndoMove()
+
+	b.UndoMove()
+	assert.Equal(t, fen, b.ToFEN())
+	assert.Equal(t, hash, b.Hash())
+	assert.Empty(t, b.MovesDone)
+}